@@ -0,0 +1,62 @@
+package eureka_client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AccessLogEntry 一次 eureka REST 调用的访问日志
+type AccessLogEntry struct {
+	// 操作名称，例如 register、heartbeat
+	Operation string
+	Method    string
+	URL       string
+	// eureka 服务端地址，URL 与 Zone 中的认证信息已替换为 ***
+	Zone string
+	// 响应状态码，为 0 表示没有收到响应
+	StatusCode int
+	Latency    time.Duration
+	// 发送请求的次数，从 1 开始，请求经过 requests.Retry 重试时大于 1
+	Attempt int
+	Err     error
+}
+
+// String 格式化为一行访问日志
+func (e AccessLogEntry) String() string {
+	return fmt.Sprintf("%s %s %d %s attempt=%d zone=%s operation=%s",
+		e.Method, e.URL, e.StatusCode, e.Latency, e.Attempt, e.Zone, e.Operation)
+}
+
+// AccessLogFunc 接收每一次 eureka REST 调用的访问日志
+type AccessLogFunc func(entry AccessLogEntry)
+
+var (
+	accessLog      AccessLogFunc
+	accessLogMutex sync.RWMutex
+)
+
+// SetAccessLog 设置 eureka REST 调用的访问日志，为 nil 则不输出
+func SetAccessLog(fn AccessLogFunc) {
+	accessLogMutex.Lock()
+	accessLog = fn
+	accessLogMutex.Unlock()
+}
+
+// NewAccessLogger 将访问日志输出到 logger 的 api 子日志，成功时为 Debug 级别，失败时为 Warn 级别
+func NewAccessLogger(logger Logger) AccessLogFunc {
+	l := ComponentLogger(logger, ComponentAPI)
+	return func(entry AccessLogEntry) {
+		if entry.Err != nil {
+			l.Warn(entry.String(), entry.Err)
+			return
+		}
+		l.Debug(entry.String())
+	}
+}
+
+func getAccessLog() AccessLogFunc {
+	accessLogMutex.RLock()
+	defer accessLogMutex.RUnlock()
+	return accessLog
+}
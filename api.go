@@ -1,10 +1,14 @@
 package eureka_client
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/godoes/eureka-client/requests"
 )
@@ -12,56 +16,294 @@ import (
 var (
 	// ErrNotFound 实例不存在，需要重新注册
 	ErrNotFound = errors.New("not found")
+	// ErrConflict 心跳时服务端返回 409，服务端的实例信息与客户端不一致，需要更新 lastDirtyTimestamp 后重新注册
+	ErrConflict = errors.New("conflict")
 )
 
+// eureka REST 操作名称
+const (
+	OpRegister             = "register"
+	OpUnRegister           = "unregister"
+	OpRefresh              = "refresh"
+	OpRefreshDelta         = "refresh_delta"
+	OpHeartbeat            = "heartbeat"
+	OpStatusOverride       = "status_override"
+	OpDeleteStatusOverride = "delete_status_override"
+)
+
+// APITracer 跟踪 eureka REST 调用，op 为操作名称，zone 为隐藏了认证信息的服务端地址
+// 返回的上下文用于发送请求，例如带有 span 的上下文，使 http 客户端的跟踪以该 span 为父级并向服务端传播；
+// 返回的函数在调用结束时执行，statusCode 为 0 表示没有收到响应
+type APITracer func(ctx context.Context, op, zone string) (context.Context, func(statusCode int, err error))
+
+var (
+	apiTracer      APITracer
+	apiTracerMutex sync.RWMutex
+)
+
+// SetAPITracer 设置 eureka REST 调用的跟踪器，为 nil 则不跟踪
+func SetAPITracer(tracer APITracer) {
+	apiTracerMutex.Lock()
+	apiTracer = tracer
+	apiTracerMutex.Unlock()
+}
+
+// trace 开始跟踪一次 eureka REST 调用，返回发送请求使用的上下文，以及在调用结束时执行的函数，同时输出访问日志
+// 传给跟踪器与访问日志的地址都隐藏了认证信息
+func trace(ctx context.Context, op, method, u, zone string) (context.Context, func(result *requests.Result, err error)) {
+	u, zone = RedactURL(u), RedactURL(zone)
+	apiTracerMutex.RLock()
+	tracer := apiTracer
+	apiTracerMutex.RUnlock()
+	end := func(int, error) {}
+	if tracer != nil {
+		ctx, end = tracer(ctx, op, zone)
+	}
+	accessLog := getAccessLog()
+	start := time.Now()
+	return ctx, func(result *requests.Result, err error) {
+		statusCode, attempt := 0, 1
+		if result != nil {
+			statusCode = result.StatusCode()
+			if result.Attempts > 1 {
+				attempt = result.Attempts
+			}
+		}
+		end(statusCode, err)
+		if accessLog != nil {
+			accessLog(AccessLogEntry{
+				Operation:  op,
+				Method:     method,
+				URL:        u,
+				Zone:       zone,
+				StatusCode: statusCode,
+				Latency:    time.Since(start),
+				Attempt:    attempt,
+				Err:        err,
+			})
+		}
+	}
+}
+
+// apiSession 创建访问 zone 的 Session，通过 Accept 请求 contentType 格式，设置了 SetHTTPDump 时输出报文
+func apiSession(client *http.Client, zone, contentType string) *requests.Session {
+	session := requests.NewSession(zone, client).Header("Accept", contentType)
+	if dump := getHTTPDump(); dump != nil {
+		session.Use(dump)
+	}
+	return session
+}
+
 // 与 eureka 服务端 rest 交互
 // https://github.com/Netflix/eureka/wiki/Eureka-REST-operations
 
-// Register 注册实例
+// 以下函数都有接受 context.Context 的 XxxContext 版本，可以设置截止时间或在退出时取消进行中的请求
+
+// Register 注册实例，成功后开始发送心跳，心跳由该 zone 共用的 BeatReactor 发送，UnRegister 成功后停止
+// 只需要注册、自行发送心跳时使用 RegisterOnly
 // POST /eureka/v2/apps/appID
 func Register(zone, app string, instance *Instance) error {
-	// Instance 服务实例
-	type InstanceInfo struct {
-		Instance *Instance `json:"instance"`
-	}
-	var info = &InstanceInfo{
-		Instance: instance,
+	return RegisterContext(context.Background(), zone, app, instance)
+}
+
+// RegisterContext 注册实例，成功后开始发送心跳，ctx 取消或超时时中止请求，不影响之后的心跳
+func RegisterContext(ctx context.Context, zone, app string, instance *Instance) error {
+	if err := RegisterOnlyContext(ctx, zone, app, instance); err != nil {
+		return err
 	}
+	zoneBeater(zone, true).AddBeatInfo(instance)
+	return nil
+}
 
+// RegisterOnly 注册实例，不会发送心跳，需要在租约过期前通过 Heartbeat 自行续约
+// POST /eureka/v2/apps/appID
+func RegisterOnly(zone, app string, instance *Instance) error {
+	return RegisterOnlyContext(context.Background(), zone, app, instance)
+}
+
+// RegisterOnlyContext 注册实例，不会发送心跳，ctx 取消或超时时中止请求
+func RegisterOnlyContext(ctx context.Context, zone, app string, instance *Instance) error {
+	return register(ctx, httpClient(), zone, app, instance, ContentTypeJSON)
+}
+
+// register 使用 client 以 contentType 格式注册实例
+func register(ctx context.Context, client *http.Client, zone, app string, instance *Instance, contentType string) error {
 	u := zone + "apps/" + app
+	ctx, end := trace(ctx, OpRegister, http.MethodPost, u, zone)
+	// 发送实例的快照，避免与修改实例状态并发
+	snapshot := instance.snapshot()
+	instance = &snapshot
 
-	// status: http.StatusNoContent
-	result := requests.Post(u).Json(info).Send().Status2xx()
-	if result.Err == nil {
-		instance.Beater.AddBeatInfo(instance)
+	session := apiSession(client, zone, contentType)
+	var result *requests.Result
+	if isXML(contentType) {
+		result = session.Post("apps/" + app).Context(ctx).Xml(&xmlInstance{Instance: instance}).Send()
+	} else {
+		// Instance 服务实例
+		type InstanceInfo struct {
+			Instance *Instance `json:"instance"`
+		}
+		var info = &InstanceInfo{
+			Instance: instance,
+		}
+		result = session.Post("apps/" + app).Context(ctx).Json(info).Send()
 	}
+	// status: http.StatusNoContent
+	result = checkUnavailable(OpRegister, zone, result).Status2xx()
+	result.Discard()
+	end(result, result.Err)
 	return result.Err
 }
 
-// UnRegister 删除实例
+// UnRegister 删除实例，成功后停止通过 Register 开始的心跳
 // DELETE /eureka/v2/apps/appID/instanceID
 func UnRegister(zone, app string, instance *Instance) error {
+	return UnRegisterContext(context.Background(), zone, app, instance)
+}
+
+// UnRegisterContext 删除实例，成功后停止通过 Register 开始的心跳，ctx 取消或超时时中止请求
+func UnRegisterContext(ctx context.Context, zone, app string, instance *Instance) error {
+	if err := unRegister(ctx, httpClient(), zone, app, instance); err != nil {
+		return err
+	}
+	if br := zoneBeater(zone, false); br != nil && br.Beating(instance.InstanceID) {
+		br.RemoveBeatInfo(app, instance.InstanceID)
+	}
+	return nil
+}
+
+// unRegister 使用 client 删除实例
+func unRegister(ctx context.Context, client *http.Client, zone, app string, instance *Instance) error {
 	u := zone + "apps/" + app + "/" + instance.InstanceID
+	ctx, end := trace(ctx, OpUnRegister, http.MethodDelete, u, zone)
 	// status: http.StatusNoContent
-	result := requests.Delete(u).Send().StatusOk()
-	if result.Err == nil && instance.Beater != nil {
-		instance.Beater.RemoveBeatInfo(app, instance.InstanceID)
+	req := apiSession(client, zone, ContentTypeJSON).Delete("apps/" + app + "/" + instance.InstanceID)
+	result := checkUnavailable(OpUnRegister, zone, req.Context(ctx).Send()).StatusOk()
+	result.Discard()
+	end(result, result.Err)
+	return result.Err
+}
+
+// StatusOverride 覆盖实例的状态，例如 OUT_OF_SERVICE，服务端以覆盖的状态对外提供该实例，直到删除覆盖或实例注销
+// PUT /eureka/v2/apps/appID/instanceID/status?value=OUT_OF_SERVICE
+func StatusOverride(zone, app, instanceID, status string) error {
+	return StatusOverrideContext(context.Background(), zone, app, instanceID, status)
+}
+
+// StatusOverrideContext 覆盖实例的状态，ctx 取消或超时时中止请求
+func StatusOverrideContext(ctx context.Context, zone, app, instanceID, status string) error {
+	return statusOverride(ctx, httpClient(), zone, app, instanceID, status)
+}
+
+// statusOverride 使用 client 覆盖实例的状态
+func statusOverride(ctx context.Context, client *http.Client, zone, app, instanceID, status string) error {
+	u := zone + "apps/" + app + "/" + instanceID + "/status"
+	params := url.Values{"value": {status}}
+	ctx, end := trace(ctx, OpStatusOverride, http.MethodPut, u+"?"+params.Encode(), zone)
+	req := apiSession(client, zone, ContentTypeJSON).Put("apps/" + app + "/" + instanceID + "/status")
+	result := checkUnavailable(OpStatusOverride, zone, req.Context(ctx).Params(params).Send())
+	if result.Err == nil && result.StatusCode() == http.StatusNotFound {
+		result.Err = ErrNotFound
+	}
+	result = result.StatusOk()
+	result.Discard()
+	end(result, result.Err)
+	return result.Err
+}
+
+// DeleteStatusOverride 删除实例的覆盖状态，并将实例状态设置为 status，例如 UP
+// DELETE /eureka/v2/apps/appID/instanceID/status?value=UP
+func DeleteStatusOverride(zone, app, instanceID, status string) error {
+	return DeleteStatusOverrideContext(context.Background(), zone, app, instanceID, status)
+}
+
+// DeleteStatusOverrideContext 删除实例的覆盖状态，ctx 取消或超时时中止请求
+func DeleteStatusOverrideContext(ctx context.Context, zone, app, instanceID, status string) error {
+	return deleteStatusOverride(ctx, httpClient(), zone, app, instanceID, status)
+}
+
+// deleteStatusOverride 使用 client 删除实例的覆盖状态
+func deleteStatusOverride(ctx context.Context, client *http.Client, zone, app, instanceID, status string) error {
+	u := zone + "apps/" + app + "/" + instanceID + "/status"
+	params := url.Values{"value": {status}}
+	ctx, end := trace(ctx, OpDeleteStatusOverride, http.MethodDelete, u+"?"+params.Encode(), zone)
+	req := apiSession(client, zone, ContentTypeJSON).Delete("apps/" + app + "/" + instanceID + "/status")
+	result := checkUnavailable(OpDeleteStatusOverride, zone, req.Context(ctx).Params(params).Send())
+	if result.Err == nil && result.StatusCode() == http.StatusNotFound {
+		result.Err = ErrNotFound
 	}
+	result = result.StatusOk()
+	result.Discard()
+	end(result, result.Err)
 	return result.Err
 }
 
 // Refresh 查询所有服务实例
 // GET /eureka/v2/apps
 func Refresh(zone string) (*Applications, error) {
-	type Result struct {
-		Applications *Applications `json:"applications"`
+	return RefreshContext(context.Background(), zone)
+}
+
+// RefreshContext 查询所有服务实例，ctx 取消或超时时中止请求
+func RefreshContext(ctx context.Context, zone string) (*Applications, error) {
+	return fetchApplications(ctx, zone, OpRefresh, "apps", fetchOptions{client: httpClient(), contentType: ContentTypeJSON})
+}
+
+// RefreshDelta 查询最近变更的服务实例，实例的 ActionType 为 ADDED、MODIFIED 或 DELETED
+// GET /eureka/v2/apps/delta
+func RefreshDelta(zone string) (*Applications, error) {
+	return RefreshDeltaContext(context.Background(), zone)
+}
+
+// RefreshDeltaContext 查询最近变更的服务实例，ctx 取消或超时时中止请求
+func RefreshDeltaContext(ctx context.Context, zone string) (*Applications, error) {
+	return fetchApplications(ctx, zone, OpRefreshDelta, "apps/delta", fetchOptions{client: httpClient(), contentType: ContentTypeJSON})
+}
+
+// fetchOptions 查询服务实例的选项
+type fetchOptions struct {
+	// 发送请求的 http.Client
+	client *http.Client
+	// 通过 Accept 请求的数据格式
+	contentType string
+	// 请求 gzip 压缩
+	gzip bool
+	// 同时拉取的远程区域
+	regions []string
+}
+
+// fetchOptions 根据配置获取查询服务实例的选项
+func (c *Config) fetchOptions() fetchOptions {
+	return fetchOptions{client: c.httpClient(), contentType: c.contentType(), gzip: c.GzipRegistry, regions: c.FetchRemoteRegionsRegistry}
+}
+
+// fetchApplications 查询服务实例，按响应的 Content-Type 与 Content-Encoding 解压并解析
+func fetchApplications(ctx context.Context, zone, op, path string, opts fetchOptions) (*Applications, error) {
+	u := zone + path
+	params := url.Values{}
+	if len(opts.regions) > 0 {
+		params.Set("regions", strings.Join(opts.regions, ","))
+		u += "?" + params.Encode()
+	}
+	ctx, end := trace(ctx, op, http.MethodGet, u, zone)
+	req := apiSession(opts.client, zone, opts.contentType).Get(path).Context(ctx).Params(params)
+	if opts.gzip {
+		req.Header("Accept-Encoding", "gzip")
 	}
-	apps := new(Applications)
-	res := &Result{
-		Applications: apps,
+	result := checkUnavailable(op, zone, req.Send()).StatusOk()
+	b, err := result.Raw()
+	if err == nil {
+		b, err = decompress(result.Resp, b)
 	}
-	u := zone + "apps"
-	err := requests.Get(u).Header("Accept", " application/json").Send().StatusOk().Json(res)
+	var apps *Applications
+	if err == nil {
+		if isXML(result.Header().Get("Content-Type")) {
+			apps, err = decodeApplicationsXML(b)
+		} else {
+			apps, err = decodeApplications(b)
+		}
+	}
+	end(result, err)
 	if err != nil {
 		return nil, err
 	}
@@ -71,20 +313,54 @@ func Refresh(zone string) (*Applications, error) {
 // Heartbeat 发送心跳
 // PUT /eureka/v2/apps/appID/instanceID
 func Heartbeat(zone, app, instanceID string) error {
-	u := zone + "apps/" + app + "/" + instanceID
+	return HeartbeatContext(context.Background(), zone, app, instanceID)
+}
+
+// HeartbeatContext 发送心跳，ctx 取消或超时时中止请求
+func HeartbeatContext(ctx context.Context, zone, app, instanceID string) error {
+	return heartbeat(ctx, httpClient(), zone, &Instance{App: app, InstanceID: instanceID}, ContentTypeJSON)
+}
+
+// heartbeat 使用 client 发送实例的心跳，通过 Accept 请求 contentType 格式
+// 与 Java 客户端一样带上实例当前的 status、lastDirtyTimestamp 与 overriddenstatus，服务端据此判断实例信息是否过期，
+// 未设置状态时（例如 Heartbeat）status 为 UP
+func heartbeat(ctx context.Context, client *http.Client, zone string, instance *Instance, contentType string) (err error) {
+	u := zone + "apps/" + instance.App + "/" + instance.InstanceID
+	state := instance.state()
+	status := state.status
+	if status == "" {
+		status = StatusUp
+	}
 	params := url.Values{
-		"status": {"UP"},
+		"status": {status},
+	}
+	if state.lastDirty != "" {
+		params.Set("lastDirtyTimestamp", state.lastDirty)
 	}
-	result := requests.Put(u).Params(params).Send()
+	if state.overriddenStatus != "" {
+		params.Set("overriddenstatus", state.overriddenStatus)
+	}
+	ctx, end := trace(ctx, OpHeartbeat, http.MethodPut, u+"?"+params.Encode(), zone)
+	req := apiSession(client, zone, contentType).Put("apps/" + instance.App + "/" + instance.InstanceID)
+	result := checkUnavailable(OpHeartbeat, zone, req.Context(ctx).Params(params).Send())
+	defer func() {
+		// 读完并关闭响应内容以复用连接
+		result.Discard()
+		end(result, err)
+	}()
 	if result.Err != nil {
 		return result.Err
 	}
 	// 心跳 404 说明eureka server重启过，需要重新注册
-	if result.Resp.StatusCode == http.StatusNotFound {
+	if result.StatusCode() == http.StatusNotFound {
 		return ErrNotFound
 	}
-	if result.Resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("heartbeat failed, invalid status code: %d", result.Resp.StatusCode)
+	// 心跳 409 说明服务端的实例信息与客户端冲突，需要重新注册
+	if result.StatusCode() == http.StatusConflict {
+		return ErrConflict
+	}
+	if result.StatusCode() != http.StatusOK {
+		return fmt.Errorf("heartbeat failed, invalid status code: %d", result.StatusCode())
 	}
 	return nil
 }
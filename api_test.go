@@ -0,0 +1,46 @@
+package eureka_client_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	eureka "github.com/godoes/eureka-client"
+	"github.com/godoes/eureka-client/eurekatest"
+)
+
+type spanKey struct{}
+
+func TestAPITracerContextUsedForRequest(t *testing.T) {
+	srv := eurekatest.NewServer()
+	defer srv.Close()
+
+	eureka.SetAPITracer(func(ctx context.Context, op, zone string) (context.Context, func(int, error)) {
+		return context.WithValue(ctx, spanKey{}, op), func(int, error) {}
+	})
+	defer eureka.SetAPITracer(nil)
+
+	var traced, requests int32
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&requests, 1)
+		if _, ok := req.Context().Value(spanKey{}).(string); ok {
+			atomic.AddInt32(&traced, 1)
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	c := eureka.NewClient(&eureka.Config{DefaultZone: srv.Zone(), App: "me", Port: 1,
+		HTTPClient: &http.Client{Transport: transport}})
+	if _, err := c.RefreshNow(); err != nil {
+		t.Fatal(err)
+	}
+	if requests == 0 || traced != requests {
+		t.Fatalf("%d of %d requests sent with the tracer's context", traced, requests)
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -2,111 +2,339 @@ package eureka_client
 
 import (
 	"context"
-	"log"
+	"errors"
 	"sync"
 	"time"
-
-	"golang.org/x/sync/semaphore"
 )
 
+// BeatReactor 发送注册实例的心跳
+// 所有实例共用一个调度 goroutine，到期的心跳交给按需启动的工作 goroutine 发送，工作 goroutine 数量不超过 beatThreadCount
 type BeatReactor struct {
 	config                   *Config
-	beatMap                  ConcurrentMap
+	beatMap                  ConcurrentMapOf[string, *BeatHandle]
 	clientBeatIntervalInSecs int64
 	beatThreadCount          int
-	beatThreadSemaphore      *semaphore.Weighted
-	beatRecordMap            ConcurrentMap
+	beatRecordMap            ConcurrentMapOf[string, beatRecord]
 	mux                      *sync.Mutex
-	log                      Logger
+	log                      LoggerV2
 	Period                   time.Duration
+	goroutines               *goroutineGroup
+	// 首次添加实例时启动，Stop 后置空
+	scheduler *beatScheduler
+	// 通过 SetPeriod 调整的实例心跳间隔
+	periods ConcurrentMapOf[string, time.Duration]
+}
+
+// BeatHandle 实例心跳的句柄，用于停止该实例的心跳
+type BeatHandle struct {
+	instance *Instance
+	done     chan struct{}
+	cancelFn func()
+	// 调整下次发送心跳的时间，SetPeriod 时使用
+	rescheduleFn func(next time.Time)
+}
+
+// Cancel 停止该实例的心跳，并等待正在发送的心跳结束，返回后不会再发送该实例的心跳
+func (h *BeatHandle) Cancel() {
+	h.cancel()
+	<-h.done
+}
+
+// Done 该实例的心跳停止后关闭
+func (h *BeatHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// cancel 停止该实例的心跳，不等待
+func (h *BeatHandle) cancel() {
+	h.cancelFn()
 }
 
 const DefaultBeatThreadNum = 20
 
-var ctx = context.Background()
+// BeatReactorOption 自定义 BeatReactor
+type BeatReactorOption func(br *BeatReactor)
 
-func NewBeatReactor(config *Config, clientBeatIntervalInSecs int64) BeatReactor {
+// WithBeatWorkers 设置同时发送心跳的工作 goroutine 上限，小于等于 0 时忽略
+func WithBeatWorkers(n int) BeatReactorOption {
+	return func(br *BeatReactor) {
+		if n > 0 {
+			br.beatThreadCount = n
+		}
+	}
+}
+
+// NewBeatReactor 创建 BeatReactor，clientBeatIntervalInSecs 为实例未设置 LeaseInfo.RenewalIntervalInSecs 时的默认心跳间隔
+// 工作 goroutine 上限默认取 Config.BeatWorkers，未设置则为 DefaultBeatThreadNum
+func NewBeatReactor(config *Config, clientBeatIntervalInSecs int64, opts ...BeatReactorOption) BeatReactor {
 	br := BeatReactor{
 		config: config,
 	}
 	if clientBeatIntervalInSecs <= 0 {
 		clientBeatIntervalInSecs = 5
 	}
-	br.beatMap = NewConcurrentMap()
+	br.beatMap = NewConcurrentMapOf[*BeatHandle]()
 	br.clientBeatIntervalInSecs = clientBeatIntervalInSecs
 	br.beatThreadCount = DefaultBeatThreadNum
-	br.beatRecordMap = NewConcurrentMap()
-	br.beatThreadSemaphore = semaphore.NewWeighted(int64(br.beatThreadCount))
+	if config.BeatWorkers > 0 {
+		br.beatThreadCount = config.BeatWorkers
+	}
+	br.beatRecordMap = NewConcurrentMapOf[beatRecord]()
+	br.periods = NewConcurrentMapOf[time.Duration]()
 	br.mux = new(sync.Mutex)
-	br.log = NewLogger()
+	br.log = ComponentLogger(NewLogger(), ComponentBeat)
 	br.Period = time.Duration(clientBeatIntervalInSecs) * time.Second
+	br.goroutines = newGoroutineGroup()
+	for _, opt := range opts {
+		opt(&br)
+	}
 	return br
 }
 
-func (br *BeatReactor) AddBeatInfo(beatInfo *Instance) {
+// SetLogger 设置心跳日志的实现
+func (br *BeatReactor) SetLogger(logger Logger) {
+	br.log = AsLoggerV2(logger)
+}
+
+// AddBeatInfo 开始发送实例的心跳，实例已存在时先停止原来的心跳，返回的句柄可用于停止该实例的心跳
+func (br *BeatReactor) AddBeatInfo(beatInfo *Instance) *BeatHandle {
 	k := beatInfo.InstanceID
 	defer br.mux.Unlock()
 	br.mux.Lock()
-	if data, ok := br.beatMap.Get(k); ok {
-		beatInfo = data.(*Instance)
-		beatInfo.Status = "UP"
-		br.beatMap.Remove(k)
+	s := br.startScheduler()
+	handle := &BeatHandle{
+		instance: beatInfo,
+		done:     make(chan struct{}),
+	}
+	entry := s.newEntry(k, handle)
+	handle.cancelFn = func() {
+		s.cancel(entry)
 	}
-	br.beatMap.Set(k, beatInfo)
-	go br.sendInstanceBeat(k, beatInfo)
+	handle.rescheduleFn = func(next time.Time) {
+		s.reschedule(entry, next)
+	}
+	// 替换并停止该实例原有的心跳，查找与替换在同一次加锁中完成
+	br.beatMap.Upsert(k, handle, func(exist bool, old *BeatHandle, handle *BeatHandle) *BeatHandle {
+		if exist {
+			old.cancel()
+		}
+		return handle
+	})
+	br.startRecord(k)
+	s.schedule(entry, time.Now())
+	return handle
 }
 
+// RemoveBeatInfo 停止实例的心跳，不等待正在发送的心跳结束
 func (br *BeatReactor) RemoveBeatInfo(serviceName string, instanceId string) {
-	log.Printf("remove beat: %s@%s from beat map", serviceName, instanceId)
+	br.log.Infof("remove beat: %s@%s from beat map", serviceName, instanceId)
 	k := instanceId
 	defer br.mux.Unlock()
 	br.mux.Lock()
-	data, exist := br.beatMap.Get(k)
-	if exist {
-		beatInfo := data.(*Instance)
-		beatInfo.Status = "UP"
+	if handle, exist := br.beatMap.Get(k); exist {
+		handle.cancel()
 	}
 	br.beatMap.Remove(k)
+	br.beatRecordMap.Remove(k)
 }
 
-func (br *BeatReactor) sendInstanceBeat(k string, beatInfo *Instance) {
+// Stop 停止所有实例的心跳，中止正在发送的心跳，并等待调度与工作 goroutine 退出
+func (br *BeatReactor) Stop() {
+	br.mux.Lock()
+	handles := make([]*BeatHandle, 0, br.beatMap.Count())
+	br.beatMap.IterCb(func(_ string, handle *BeatHandle) {
+		handles = append(handles, handle)
+	})
+	br.beatMap.Clear()
+	br.beatRecordMap.Clear()
+	s := br.scheduler
+	br.scheduler = nil
+	br.mux.Unlock()
+
+	for _, handle := range handles {
+		handle.Cancel()
+	}
+	if s != nil {
+		s.shutdown()
+		s.wg.Wait()
+	}
+}
+
+// startScheduler 启动调度 goroutine，调用时需持有 br.mux
+func (br *BeatReactor) startScheduler() *beatScheduler {
+	if br.scheduler == nil {
+		s := newBeatScheduler(br.beatThreadCount)
+		br.scheduler = s
+		s.wg.Add(1)
+		br.goroutines.Go(GoroutineBeat, func() {
+			defer s.wg.Done()
+			br.runScheduler(s)
+		})
+	}
+	return br.scheduler
+}
+
+// runScheduler 按时间顺序分发到期的心跳任务
+func (br *BeatReactor) runScheduler(s *beatScheduler) {
 	for {
-		err := br.beatThreadSemaphore.Acquire(ctx, 1)
-		if err != nil {
-			log.Printf("sendInstanceBeat failed to acquire semaphore: %v", err)
+		entry, wait := s.next(time.Now())
+		if entry != nil {
+			br.dispatch(s, entry)
+			continue
+		}
+
+		var timeout <-chan time.Time
+		var t *time.Timer
+		if wait > 0 {
+			t = time.NewTimer(wait)
+			timeout = t.C
+		}
+		select {
+		case <-timeout:
+		case <-s.wakeup:
+		case <-s.stop:
+		}
+		if t != nil {
+			t.Stop()
+		}
+		select {
+		case <-s.stop:
 			return
+		default:
 		}
-		//如果当前实例注销，则进行停止心跳
-		if beatInfo.Status != "UP" {
-			log.Printf("instance[%s] stop heartBeating", k)
-			br.beatThreadSemaphore.Release(1)
+	}
+}
+
+// dispatch 将心跳任务交给工作 goroutine，没有空闲的工作 goroutine 时按需启动
+func (br *BeatReactor) dispatch(s *beatScheduler, entry *beatEntry) {
+	if s.reserveWorker() {
+		s.wg.Add(1)
+		br.goroutines.Go(GoroutineBeat, func() {
+			defer s.wg.Done()
+			br.work(s)
+		})
+	}
+	select {
+	case s.tasks <- entry:
+	case <-s.stop:
+		s.finish(entry)
+	}
+}
+
+// work 工作 goroutine，发送心跳直到调度器停止
+func (br *BeatReactor) work(s *beatScheduler) {
+	for {
+		s.setIdle(1)
+		select {
+		case entry := <-s.tasks:
+			s.setIdle(-1)
+			br.sendInstanceBeat(s, entry)
+		case <-s.stop:
+			s.setIdle(-1)
 			return
 		}
+	}
+}
 
+// sendInstanceBeat 发送一次实例心跳，并调度下一次心跳
+func (br *BeatReactor) sendInstanceBeat(s *beatScheduler, entry *beatEntry) {
+	k, handle := entry.key, entry.handle
+	beatInfo := handle.instance
+	if !s.isCancelled(entry) {
+		// 实例在所有状态下都发送心跳，DOWN、OUT_OF_SERVICE 等状态的实例停止心跳后会被服务端剔除，注销时通过 RemoveBeatInfo 停止
 		//进行心跳通信
-		// /eureka/apps/ORDER-SERVICE/localhost:order-service:8886?status=UP
-		err = Heartbeat(br.config.DefaultZone, beatInfo.App, beatInfo.InstanceID)
-		//u := br.config.DefaultZone + "apps/" + beatInfo.App + "/" + beatInfo.InstanceID + "?status=UP"
-		//result := requests.Put(u).Send().Status2xx()
-
+		// /eureka/apps/ORDER-SERVICE/localhost:order-service:8886?status=UP&lastDirtyTimestamp=...&overriddenstatus=UNKNOWN
+		err := heartbeat(entry.ctx, br.config.httpClient(), br.config.DefaultZone, beatInfo, br.config.contentType())
+		if errors.Is(err, context.Canceled) {
+			// 心跳已取消，下面的 schedule 会结束该任务
+			s.schedule(entry, time.Now())
+			return
+		}
+		br.record(k, err)
 		if err != nil {
-			log.Printf("beat to server return error:%+v", err)
+			br.log.Warn("beat to server return error", err)
 			if err == ErrNotFound {
-				log.Printf("can't find this instance, heart beat exist. key:%s", k)
-				br.beatMap.Remove(k)
-				return
-			} else {
-				br.beatThreadSemaphore.Release(1)
-				t := time.NewTimer(br.Period)
-				<-t.C
-				continue
+				// 服务端已剔除该实例，重新注册后继续发送心跳，注册失败时下一次心跳会再次尝试
+				br.reRegister(entry.ctx, beatInfo, "instance not found on server")
+			} else if err == ErrConflict {
+				br.reRegister(entry.ctx, beatInfo, "heartbeat conflict")
+			} else if br.leaseExpiring(k, beatInfo) {
+				// 租约即将过期，不等服务端剔除后返回 404，提前重新注册
+				br.reRegister(entry.ctx, beatInfo, "lease is about to expire")
 			}
 		}
+	}
+	s.schedule(entry, time.Now().Add(jitter(br.period(beatInfo), br.config.IntervalJitterPercent)))
+}
 
-		br.beatRecordMap.Set(k, time.Now().UnixNano()/1e6)
-		br.beatThreadSemaphore.Release(1)
+// SetPeriod 调整实例的心跳间隔，优先于实例的 LeaseInfo.RenewalIntervalInSecs，正在发送心跳的实例立即按新的间隔重新调度
+// 只调整原有心跳任务的下次心跳时间，心跳句柄与最近一次心跳的记录保持不变
+func (br *BeatReactor) SetPeriod(instanceID string, period time.Duration) {
+	defer br.mux.Unlock()
+	br.mux.Lock()
+	br.periods.Set(instanceID, period)
+	if handle, ok := br.beatMap.Get(instanceID); ok {
+		handle.rescheduleFn(time.Now().Add(jitter(br.period(handle.instance), br.config.IntervalJitterPercent)))
+	}
+}
+
+// period 实例的心跳间隔，优先使用 SetPeriod 设置的间隔，其次是实例的 LeaseInfo.RenewalIntervalInSecs，都未设置则使用 Period
+func (br *BeatReactor) period(instance *Instance) time.Duration {
+	if period, ok := br.periods.Get(instance.InstanceID); ok && period > 0 {
+		return period
+	}
+	if instance.LeaseInfo != nil && instance.LeaseInfo.RenewalIntervalInSecs > 0 {
+		return time.Duration(instance.LeaseInfo.RenewalIntervalInSecs) * time.Second
+	}
+	return br.Period
+}
 
-		t := time.NewTimer(br.Period)
-		<-t.C
+// Beating 实例是否正在发送心跳，实例注销后返回 false
+func (br *BeatReactor) Beating(instanceID string) bool {
+	return br.beatMap.Has(instanceID)
+}
+
+// reRegister 服务端不存在实例、心跳冲突或租约即将过期时更新实例的 lastDirtyTimestamp 并重新注册
+func (br *BeatReactor) reRegister(ctx context.Context, instance *Instance, reason string) {
+	instance.setDirty(br.config.clock().Now())
+	err := register(ctx, br.config.httpClient(), br.config.DefaultZone, instance.App, instance, br.config.contentType())
+	if err != nil {
+		br.log.Error(reason+", re-register instance failed", err)
+		return
+	}
+	br.log.Infof("%s, re-register instance[%s] with lastDirtyTimestamp %s", reason, instance.InstanceID, instance.state().lastDirty)
+}
+
+// leaseExpiring 实例自最近一次成功心跳以来租约是否即将过期，即距离过期不足一个心跳间隔
+func (br *BeatReactor) leaseExpiring(k string, instance *Instance) bool {
+	if instance.LeaseInfo == nil || instance.LeaseInfo.DurationInSecs <= 0 {
+		return false
+	}
+	last, ok := br.LastBeat(k)
+	if !ok {
+		return false
+	}
+	lease := time.Duration(instance.LeaseInfo.DurationInSecs)*time.Second - br.period(instance)
+	return br.config.clock().Now().Sub(last) >= lease
+}
+
+var (
+	zoneBeatersMutex sync.Mutex
+	// 包级的 Register 发送心跳使用的 BeatReactor，每个 zone 一个
+	zoneBeaters map[string]*BeatReactor
+)
+
+// zoneBeater 获取 zone 对应的 BeatReactor，不存在时 create 为 true 则创建，否则返回 nil
+func zoneBeater(zone string, create bool) *BeatReactor {
+	zoneBeatersMutex.Lock()
+	defer zoneBeatersMutex.Unlock()
+	if br, ok := zoneBeaters[zone]; ok || !create {
+		return br
+	}
+	if zoneBeaters == nil {
+		zoneBeaters = make(map[string]*BeatReactor)
 	}
+	beater := NewBeatReactor(&Config{DefaultZone: zone}, 30)
+	zoneBeaters[zone] = &beater
+	return &beater
 }
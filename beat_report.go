@@ -0,0 +1,105 @@
+package eureka_client
+
+import (
+	"sort"
+	"time"
+)
+
+// beatRecord 实例的心跳记录
+type beatRecord struct {
+	// 开始发送心跳的时间
+	added time.Time
+	// 最近一次成功心跳的时间
+	last time.Time
+	// 连续失败次数，成功后清零
+	failures int
+}
+
+// BeatRecord 实例的心跳情况
+type BeatRecord struct {
+	InstanceID string
+	App        string
+	// 最近一次成功心跳的时间，为零值表示还没有成功过
+	LastBeat time.Time
+	// 心跳间隔
+	Interval time.Duration
+	// 连续失败次数，成功后清零
+	ConsecutiveFailures int
+	// 超过 2 个心跳间隔没有成功心跳，续约可能已经中断
+	Overdue bool
+}
+
+// BeatReport BeatReactor 中所有实例的心跳情况
+type BeatReport struct {
+	// 按 InstanceID 排序
+	Instances []BeatRecord
+	// 超过 2 个心跳间隔没有成功心跳的实例数量
+	Overdue int
+}
+
+// startRecord 实例开始发送心跳时重置心跳记录
+func (br *BeatReactor) startRecord(k string) {
+	br.beatRecordMap.Set(k, beatRecord{added: br.config.clock().Now()})
+}
+
+// record 记录一次心跳结果，实例已停止心跳时忽略
+func (br *BeatReactor) record(k string, err error) {
+	if !br.beatMap.Has(k) {
+		return
+	}
+	now := br.config.clock().Now()
+	br.beatRecordMap.Upsert(k, beatRecord{}, func(exist bool, valueInMap beatRecord, _ beatRecord) beatRecord {
+		r := beatRecord{added: now}
+		if exist {
+			r = valueInMap
+		}
+		if err != nil {
+			r.failures++
+		} else {
+			r.failures = 0
+			r.last = now
+		}
+		return r
+	})
+}
+
+// LastBeat 获取实例最近一次成功心跳的时间，实例不在 BeatReactor 中或者还没有成功心跳时返回 false
+func (br *BeatReactor) LastBeat(instanceID string) (time.Time, bool) {
+	r, ok := br.beatRecordMap.Get(instanceID)
+	if !ok || r.last.IsZero() {
+		return time.Time{}, false
+	}
+	return r.last, true
+}
+
+// Report 获取 BeatReactor 中所有正在发送心跳的实例的心跳情况
+func (br *BeatReactor) Report() BeatReport {
+	now := br.config.clock().Now()
+	report := BeatReport{}
+	br.beatMap.IterCb(func(k string, handle *BeatHandle) {
+		instance := handle.instance
+		record := BeatRecord{
+			InstanceID: k,
+			App:        instance.App,
+			Interval:   br.period(instance),
+		}
+		since := now
+		if r, ok := br.beatRecordMap.Get(k); ok {
+			record.LastBeat = r.last
+			record.ConsecutiveFailures = r.failures
+			since = r.added
+			if !r.last.IsZero() {
+				since = r.last
+			}
+		}
+		if now.Sub(since) > 2*record.Interval {
+			record.Overdue = true
+			report.Overdue++
+		}
+		report.Instances = append(report.Instances, record)
+	})
+	sort.Slice(report.Instances, func(i, j int) bool {
+		return report.Instances[i].InstanceID < report.Instances[j].InstanceID
+	})
+	return report
+}
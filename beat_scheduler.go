@@ -0,0 +1,201 @@
+package eureka_client
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// beatEntry 调度器中一个实例的心跳任务
+type beatEntry struct {
+	key    string
+	handle *BeatHandle
+	// 心跳请求的上下文，任务取消或调度器停止时取消，中止正在发送的心跳
+	ctx      context.Context
+	cancelFn context.CancelFunc
+	// 下次发送心跳的时间
+	next time.Time
+	// 在堆中的位置，-1 表示不在堆中（正在发送心跳）
+	index int
+	// 已取消，不再调度
+	cancelled bool
+}
+
+// beatQueue 按下次心跳时间排序的最小堆
+type beatQueue []*beatEntry
+
+func (q beatQueue) Len() int { return len(q) }
+
+func (q beatQueue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+
+func (q beatQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *beatQueue) Push(x interface{}) {
+	entry := x.(*beatEntry)
+	entry.index = len(*q)
+	*q = append(*q, entry)
+}
+
+func (q *beatQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	entry.index = -1
+	*q = old[:n-1]
+	return entry
+}
+
+// beatScheduler 心跳调度器，单个 goroutine 按时间顺序取出到期的任务，交给有界的工作池发送心跳
+// 工作 goroutine 按需启动，数量不超过 maxWorkers
+type beatScheduler struct {
+	mutex      sync.Mutex
+	queue      beatQueue
+	maxWorkers int
+	workers    int
+	idle       int
+
+	// 调度器的上下文，任务的上下文都派生于此，停止调度器时取消
+	ctx      context.Context
+	cancelFn context.CancelFunc
+
+	tasks  chan *beatEntry
+	wakeup chan struct{}
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newBeatScheduler(maxWorkers int) *beatScheduler {
+	if maxWorkers <= 0 {
+		maxWorkers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &beatScheduler{
+		maxWorkers: maxWorkers,
+		ctx:        ctx,
+		cancelFn:   cancel,
+		tasks:      make(chan *beatEntry),
+		wakeup:     make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+	}
+}
+
+// newEntry 创建实例的心跳任务，任务的上下文派生于调度器的上下文
+func (s *beatScheduler) newEntry(key string, handle *BeatHandle) *beatEntry {
+	ctx, cancel := context.WithCancel(s.ctx)
+	return &beatEntry{key: key, handle: handle, ctx: ctx, cancelFn: cancel, index: -1}
+}
+
+// shutdown 取消调度器的上下文，并通知调度与工作 goroutine 退出
+func (s *beatScheduler) shutdown() {
+	s.cancelFn()
+	close(s.stop)
+}
+
+// schedule 在 next 时间调度任务，任务已取消时结束该任务
+func (s *beatScheduler) schedule(entry *beatEntry, next time.Time) {
+	s.mutex.Lock()
+	if entry.cancelled {
+		s.mutex.Unlock()
+		close(entry.handle.done)
+		return
+	}
+	entry.next = next
+	heap.Push(&s.queue, entry)
+	s.mutex.Unlock()
+	s.notify()
+}
+
+// reschedule 将等待中的任务调整到 next 时间，正在发送心跳的任务由工作 goroutine 返回后按新的间隔调度
+func (s *beatScheduler) reschedule(entry *beatEntry, next time.Time) {
+	s.mutex.Lock()
+	if entry.cancelled || entry.index < 0 {
+		s.mutex.Unlock()
+		return
+	}
+	entry.next = next
+	heap.Fix(&s.queue, entry.index)
+	s.mutex.Unlock()
+	s.notify()
+}
+
+// cancel 取消任务，任务在堆中时立即结束，正在发送心跳时中止请求，由工作 goroutine 返回后结束
+func (s *beatScheduler) cancel(entry *beatEntry) {
+	s.mutex.Lock()
+	if entry.cancelled {
+		s.mutex.Unlock()
+		return
+	}
+	entry.cancelled = true
+	entry.cancelFn()
+	queued := entry.index >= 0
+	if queued {
+		heap.Remove(&s.queue, entry.index)
+	}
+	s.mutex.Unlock()
+	if queued {
+		close(entry.handle.done)
+		s.notify()
+	}
+}
+
+// finish 结束任务，调度器停止时未能交给工作 goroutine 的任务使用
+func (s *beatScheduler) finish(entry *beatEntry) {
+	s.mutex.Lock()
+	entry.cancelled = true
+	entry.cancelFn()
+	s.mutex.Unlock()
+	close(entry.handle.done)
+}
+
+// isCancelled 任务是否已取消
+func (s *beatScheduler) isCancelled(entry *beatEntry) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return entry.cancelled
+}
+
+// notify 唤醒调度 goroutine 重新计算等待时间
+func (s *beatScheduler) notify() {
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// next 取出到期的任务，没有到期的任务时返回需要等待的时间，堆为空时等待时间为负数
+func (s *beatScheduler) next(now time.Time) (*beatEntry, time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.queue) == 0 {
+		return nil, -1
+	}
+	head := s.queue[0]
+	if wait := head.next.Sub(now); wait > 0 {
+		return nil, wait
+	}
+	return heap.Pop(&s.queue).(*beatEntry), 0
+}
+
+// reserveWorker 没有空闲的工作 goroutine 且未达到上限时，返回 true 表示需要启动一个新的工作 goroutine
+func (s *beatScheduler) reserveWorker() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.idle > 0 || s.workers >= s.maxWorkers {
+		return false
+	}
+	s.workers++
+	return true
+}
+
+// setIdle 更新空闲的工作 goroutine 数量
+func (s *beatScheduler) setIdle(delta int) {
+	s.mutex.Lock()
+	s.idle += delta
+	s.mutex.Unlock()
+}
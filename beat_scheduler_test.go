@@ -0,0 +1,122 @@
+package eureka_client
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestEntry(s *beatScheduler, key string) *beatEntry {
+	return s.newEntry(key, &BeatHandle{done: make(chan struct{})})
+}
+
+func isDone(entry *beatEntry) bool {
+	select {
+	case <-entry.handle.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestBeatScheduler(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		// 在 a（now+1s）、b（now+2s）、c（now+3s）调度后执行
+		act func(s *beatScheduler, entries map[string]*beatEntry)
+		// 到 now+10s 为止依次取出的任务
+		want []string
+		// handle 已结束的任务
+		done []string
+	}{
+		{"order by next", func(s *beatScheduler, entries map[string]*beatEntry) {}, []string{"a", "b", "c"}, nil},
+		{"reschedule earlier", func(s *beatScheduler, entries map[string]*beatEntry) {
+			s.reschedule(entries["c"], now)
+		}, []string{"c", "a", "b"}, nil},
+		{"reschedule later", func(s *beatScheduler, entries map[string]*beatEntry) {
+			s.reschedule(entries["a"], now.Add(5*time.Second))
+		}, []string{"b", "c", "a"}, nil},
+		{"cancel queued", func(s *beatScheduler, entries map[string]*beatEntry) {
+			s.cancel(entries["b"])
+		}, []string{"a", "c"}, []string{"b"}},
+		{"cancel twice", func(s *beatScheduler, entries map[string]*beatEntry) {
+			s.cancel(entries["a"])
+			s.cancel(entries["a"])
+		}, []string{"b", "c"}, []string{"a"}},
+		{"reschedule cancelled", func(s *beatScheduler, entries map[string]*beatEntry) {
+			s.cancel(entries["c"])
+			s.reschedule(entries["c"], now)
+		}, []string{"a", "b"}, []string{"c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newBeatScheduler(1)
+			defer s.shutdown()
+			entries := make(map[string]*beatEntry)
+			for i, key := range []string{"a", "b", "c"} {
+				entries[key] = newTestEntry(s, key)
+				s.schedule(entries[key], now.Add(time.Duration(i+1)*time.Second))
+			}
+			tt.act(s, entries)
+
+			got := make([]string, 0)
+			for {
+				entry, wait := s.next(now.Add(10 * time.Second))
+				if entry == nil {
+					if wait >= 0 {
+						t.Fatalf("wait = %s with every task due", wait)
+					}
+					break
+				}
+				got = append(got, entry.key)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("order = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("order = %v, want %v", got, tt.want)
+				}
+			}
+			for key, entry := range entries {
+				want := false
+				for _, d := range tt.done {
+					want = want || d == key
+				}
+				if isDone(entry) != want {
+					t.Fatalf("entry %s done = %v, want %v", key, isDone(entry), want)
+				}
+				if want && entry.ctx.Err() == nil {
+					t.Fatalf("entry %s context not cancelled", key)
+				}
+			}
+		})
+	}
+}
+
+func TestBeatSchedulerInFlight(t *testing.T) {
+	now := time.Now()
+	s := newBeatScheduler(1)
+	defer s.shutdown()
+	entry := newTestEntry(s, "a")
+	s.schedule(entry, now)
+	if got, _ := s.next(now); got != entry {
+		t.Fatal("due entry not returned")
+	}
+
+	// 正在发送心跳的任务不在堆中，reschedule 不会把它放回堆中
+	s.reschedule(entry, now)
+	if got, wait := s.next(now); got != nil || wait >= 0 {
+		t.Fatalf("next = %v, %s, want empty queue", got, wait)
+	}
+	// 取消时中止请求，由工作 goroutine 返回后结束
+	s.cancel(entry)
+	if entry.ctx.Err() == nil || isDone(entry) {
+		t.Fatal("in-flight entry should be aborted but not finished")
+	}
+	// 工作 goroutine 返回后重新调度已取消的任务时结束该任务
+	s.schedule(entry, now.Add(time.Second))
+	if !isDone(entry) {
+		t.Fatal("cancelled entry not finished when scheduled again")
+	}
+}
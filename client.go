@@ -1,70 +1,151 @@
 package eureka_client
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 )
 
 // Client eureka客户端
 type Client struct {
-	logger Logger
+	logger  LoggerV2
+	metrics Metrics
+	stats   statsRecorder
+	// 通过 SetLogger 设置的日志，logger 与 BeatReactor 的日志都是它附加了 component 字段的子日志
+	baseLogger LoggerV2
+
+	// 最近从注册表中消失的实例
+	tombstones tombstones
+	// 增量变更日志
+	changeLog changeLog
+	// 后台 goroutine
+	goroutines goroutineGroup
 
 	// for monitor system signal
 	signalChan chan os.Signal
-	mutex      sync.RWMutex
-	running    bool
+	// SetIntervals 调整间隔后唤醒心跳与刷新循环
+	heartbeatWakeup chan struct{}
+	refreshWakeup   chan struct{}
+	// 注销前后执行的钩子
+	beforeDeregister []ShutdownHook
+	afterDeregister  []ShutdownHook
+	mutex            sync.RWMutex
+	// 生命周期状态，Start 与 Stop 持有 lifecycleMutex 依次执行
+	state          lifecycleState
+	lifecycleMutex sync.Mutex
+	// Stop 时关闭，通知后台 goroutine 退出
+	stop chan struct{}
+	// 后台心跳、注册与拉取服务列表的请求使用的上下文，Stop 时取消，中止正在进行的请求
+	ctx    context.Context
+	cancel context.CancelFunc
+	// 对外的客户端状态与接收状态变化的 channel
+	stateMutex     sync.Mutex
+	clientState    ClientState
+	stateListeners []chan ClientState
+	// DiscoveryClient 的实现，以及 Watch 的订阅
+	resolver   *Resolver
+	watchMutex sync.Mutex
+	watchers   map[*watcher]struct{}
+	// 客户端停止后为 true，之后的 Watch 只收到当前的实例
+	watchClosed bool
+	// 实例已通过 Deregister 注销，心跳循环不再重新注册
+	deregistered bool
+	// 保证同一时间只有一次拉取服务列表，避免定时刷新与 RefreshNow 并发合并增量
+	refreshMutex sync.Mutex
+	// 服务列表变化时执行的回调
+	registryListeners []RegistryListener
+	// 生命周期事件的回调
+	hooks []LifecycleHooks
 
 	Config   *Config
-	Instance *Instance
+	Instance *RegisteredInstance
+	// 通过 AddInstance 注册的其他实例
+	instances map[string]*RegisteredInstance
 
 	// eureka服务中注册的应用
+	//
+	// Deprecated: 后台刷新时会被替换，直接读取存在数据竞争，使用 GetApplications 获取深拷贝
 	Applications *Applications
+	// 服务列表的代数，每次更新服务列表后递增
+	generation uint64
 }
 
 // Option 自定义
 type Option func(instance *Instance)
 
-// SetLogger 设置日志实现
+// SetLogger 设置日志实现，客户端与实例的 BeatReactor 分别使用附加了 component=client 与 component=beat 的子日志
 func (c *Client) SetLogger(logger Logger) {
-	c.logger = logger
+	c.baseLogger = AsLoggerV2(logger)
+	c.logger = ComponentLogger(c.baseLogger, ComponentClient)
+	for _, beater := range c.beaters() {
+		beater.SetLogger(ComponentLogger(c.baseLogger, ComponentBeat))
+	}
+}
+
+// Logger 获取通过 SetLogger 设置的日志（未附加 component 字段），默认附加了 app、instanceId、zone 字段，
+// 可以传给 NewAccessLogger 或 NewLogWriter，使访问日志与 http 报文和客户端日志的格式、输出一致
+func (c *Client) Logger() LoggerV2 {
+	return c.baseLogger
+}
+
+// SetMetrics 设置运行指标的观察者
+func (c *Client) SetMetrics(metrics Metrics) {
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	c.metrics = metrics
 }
 
-// Start 启动时注册客户端，并后台刷新服务列表，以及心跳
+// Start 启动时注册客户端，并后台刷新服务列表，以及心跳，重复调用不会重复启动，需要判断是否启动成功时使用 StartE
 func (c *Client) Start() {
-	c.mutex.Lock()
-	c.running = true
-	c.mutex.Unlock()
-	// 刷新服务列表
-	go c.refresh()
-	// 心跳
-	go c.heartbeat()
-	// 监听退出信号，自动删除注册信息
-	go c.handleSignal()
+	if err := c.StartE(); err != nil {
+		c.logger.Error("start client failed", err)
+	}
 }
 
 // refresh 刷新服务列表
 func (c *Client) refresh() {
 	timer := time.NewTimer(0)
-	interval := time.Duration(c.Config.RegistryFetchIntervalSeconds) * time.Second
-	for c.running {
-		<-timer.C
+	// 服务端连续返回 503 的次数，用于放慢拉取频率
+	unavailable := 0
+	defer timer.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-timer.C:
+		case <-c.refreshWakeup:
+			// 间隔已调整，按新的间隔重新计时
+			resetTimer(timer, c.refreshWait(unavailable))
+			continue
+		}
 
-		if err := c.doRefresh(); err != nil {
-			c.logger.Error("refresh application instance failed", err)
+		err := c.doRefresh()
+		if err != nil {
+			c.opLogger(OpRefresh).Error("refresh application instance failed", err)
 		} else {
-			c.logger.Debug("refresh application instance successful")
+			c.opLogger(OpRefresh).Debug("refresh application instance successful")
+		}
+		if errors.Is(err, ErrServerUnavailable) {
+			unavailable++
+		} else {
+			unavailable = 0
 		}
 
 		// reset interval
-		timer.Reset(interval)
+		timer.Reset(c.refreshWait(unavailable))
 	}
-	// stop
-	timer.Stop()
+}
+
+// refreshWait 下一次拉取服务列表前的等待时间，服务端连续不可用时退避
+func (c *Client) refreshWait(unavailable int) time.Duration {
+	_, interval := c.intervals()
+	return jitter(backoff(interval, unavailable, unavailableFetchBackoffBound), c.Config.IntervalJitterPercent)
 }
 
 // ConnectDetection 连接检测
@@ -85,95 +166,301 @@ func (c *Client) ConnectDetection() error {
 // heartbeat 心跳
 func (c *Client) heartbeat() {
 	timer := time.NewTimer(0)
-	interval := time.Duration(c.Config.RenewalIntervalInSecs) * time.Second
-	for c.running {
-		<-timer.C
+	// 连续失败次数，用于计算退避间隔
+	failures := 0
+	// 最近一次成功续约（心跳或注册）的时间
+	var renewedAt time.Time
+	defer timer.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-timer.C:
+		case <-c.heartbeatWakeup:
+			// 间隔已调整，按新的间隔重新计时
+			resetTimer(timer, c.heartbeatWait(failures, renewedAt))
+			continue
+		}
+		if c.isDeregistered() {
+			interval, _ := c.intervals()
+			timer.Reset(interval)
+			continue
+		}
 
 		err := c.doHeartbeat()
+		c.recordHeartbeat(err)
+		if err != nil {
+			c.onHeartbeatError(err)
+		}
 		if err == nil {
-			c.logger.Debug("heartbeat application instance successful")
-		} else if err == ErrNotFound {
+			c.opLogger(OpHeartbeat).Debug("heartbeat application instance successful")
+		} else if c.leaseExpiring(renewedAt) {
+			// 租约即将过期，不等服务端剔除后返回 404，提前重新注册
+			c.opLogger(OpHeartbeat).Warn("heartbeat failed and lease is about to expire, re-register application instance", err)
+			err = c.doRegister()
+			if err == nil {
+				c.opLogger(OpRegister).Info("register application instance successful")
+			} else {
+				c.opLogger(OpRegister).Error("register application instance failed", err)
+			}
+		} else if err == ErrNotFound || err == ErrConflict {
+			if err == ErrConflict {
+				// 服务端的实例信息与客户端冲突，更新 lastDirtyTimestamp 后重新注册
+				c.opLogger(OpHeartbeat).Warn("heartbeat conflict, re-register with current lastDirtyTimestamp", err)
+				c.Instance.setDirty(c.Config.clock().Now())
+			}
 			// heartbeat not found, need register
 			err = c.doRegister()
 			if err == nil {
-				c.logger.Info("register application instance successful")
+				c.opLogger(OpRegister).Info("register application instance successful")
 			} else {
-				c.logger.Error("register application instance failed", err)
+				c.opLogger(OpRegister).Error("register application instance failed", err)
 			}
+		} else if c.reRegisterDue(failures + 1) {
+			c.opLogger(OpHeartbeat).Warn(fmt.Sprintf("heartbeat failed %d times in a row, re-register application instance", failures+1), err)
+			err = c.reRegister(failures + 1)
 		} else {
-			c.logger.Error("heartbeat application instance failed", err)
+			c.opLogger(OpHeartbeat).Error("heartbeat application instance failed", err)
+		}
+		failures = c.updateDegraded(failures, err)
+		if err == nil {
+			renewedAt = c.Config.clock().Now()
+			c.stats.renewed(renewedAt)
 		}
+		c.updateState()
 
 		// reset interval
-		timer.Reset(interval)
+		timer.Reset(c.heartbeatWait(failures, renewedAt))
 	}
-	// stop
-	timer.Stop()
+}
+
+// heartbeatWait 下一次心跳前的等待时间，连续失败时退避，退避间隔不超过租约即将过期的时间
+func (c *Client) heartbeatWait(failures int, renewedAt time.Time) time.Duration {
+	interval, _ := c.intervals()
+	wait := jitter(backoff(interval, failures, c.Config.HeartbeatBackoffBound), c.Config.IntervalJitterPercent)
+	if remaining := c.leaseRemaining(renewedAt); remaining > 0 && wait > remaining {
+		wait = remaining
+	}
+	return wait
 }
 
 func (c *Client) doRegister() error {
-	return Register(c.Config.DefaultZone, c.Config.App, c.Instance)
+	err := c.Instance.RegisterContext(c.ctx)
+	if err == nil {
+		c.onRegistered()
+	}
+	return err
 }
 
-func (c *Client) doUnRegister() error {
-	return UnRegister(c.Config.DefaultZone, c.Instance.App, c.Instance)
+func (c *Client) doUnRegister(ctx context.Context) error {
+	return c.Instance.UnRegisterContext(ctx)
 }
 
 func (c *Client) doHeartbeat() error {
-	return Heartbeat(c.Config.DefaultZone, c.Instance.App, c.Instance.InstanceID)
+	return heartbeat(c.ctx, c.Config.httpClient(), c.Config.DefaultZone, c.Instance.Instance, c.Config.contentType())
 }
 
 func (c *Client) doRefresh() error {
-	// todo If the delta is disabled or if it is the first time, get all applications
-
-	// get all applications
-	applications, err := Refresh(c.Config.DefaultZone)
+	c.refreshMutex.Lock()
+	defer c.refreshMutex.Unlock()
+	start := c.Config.clock().Now()
+	applications, err := c.fetchRegistry()
+	c.recordFetch(applications, c.Config.clock().Now().Sub(start), err)
 	if err != nil {
 		return err
 	}
 
-	// set applications
+	// set applications，实例没有任何变化时保留原来的服务列表
+	// 哈希只统计各状态的实例数，替换实例后哈希不变，不能用来判断服务列表是否变化
+	c.mutex.RLock()
+	old := c.Applications
+	c.mutex.RUnlock()
+	diff := diffRegistry(old, applications)
+	if old != nil && diff.Empty() {
+		c.opLogger(OpRefresh).Debugf("registry unchanged, apps hashcode: %s", applications.AppsHashcode)
+		c.onRegistryRefreshed()
+		return nil
+	}
 	c.mutex.Lock()
 	c.Applications = applications
+	c.generation++
 	c.mutex.Unlock()
+
+	c.tombstones.update(old, applications, time.Duration(c.Config.TombstoneWindowSecs)*time.Second, c.Config.clock().Now())
+	c.reportRegistryDiff(diff)
+	c.notifyWatchers()
+	c.onRegistryRefreshed()
 	return nil
 }
 
-// handleSignal 监听退出信号，删除注册的实例
+// RefreshNow 立即拉取一次服务列表，返回更新后的服务列表的深拷贝
+// 可以在发布后或者查询不到预期的服务时使用，不影响后台的定时刷新
+func (c *Client) RefreshNow() (*Applications, error) {
+	if err := c.doRefresh(); err != nil {
+		return nil, err
+	}
+	return c.GetApplications(), nil
+}
+
+// fetchRegistry 拉取服务列表，禁用增量或者首次拉取时全量拉取，否则增量拉取并合并到本地缓存
+// 合并后的哈希与服务端不一致时，先尝试在最近一次全量快照上重放变更日志，仍不一致则全量拉取
+func (c *Client) fetchRegistry() (*Applications, error) {
+	c.mutex.RLock()
+	current := c.Applications
+	c.mutex.RUnlock()
+	if c.Config.DisableDelta || current == nil {
+		return c.fetchFullRegistry()
+	}
+
+	delta, err := fetchApplications(c.ctx, c.Config.DefaultZone, OpRefreshDelta, "apps/delta", c.Config.fetchOptions())
+	if err != nil {
+		return nil, err
+	}
+	applications := copyApplications(current)
+	applyDelta(applications, delta)
+	c.changeLog.append(delta, c.Config.clock().Now())
+	applications.AppsHashcode = delta.AppsHashcode
+	applications.VersionsDelta = delta.VersionsDelta
+	if delta.AppsHashcode == "" || reconcileHashcode(applications) == delta.AppsHashcode {
+		return applications, nil
+	}
+
+	if rebuilt, ok := c.changeLog.rebuild(); ok && reconcileHashcode(rebuilt) == delta.AppsHashcode {
+		c.opLogger(OpRefreshDelta).Info("registry hashcode mismatch, rebuilt from change log")
+		rebuilt.AppsHashcode = delta.AppsHashcode
+		rebuilt.VersionsDelta = delta.VersionsDelta
+		return rebuilt, nil
+	}
+	c.opLogger(OpRefreshDelta).Warnf("registry hashcode mismatch, local: %s, server: %s, fetch full registry",
+		reconcileHashcode(applications), delta.AppsHashcode)
+	return c.fetchFullRegistry()
+}
+
+// fetchFullRegistry 全量拉取服务列表，并以其为基础重置变更日志
+func (c *Client) fetchFullRegistry() (*Applications, error) {
+	applications, err := fetchApplications(c.ctx, c.Config.DefaultZone, OpRefresh, "apps", c.Config.fetchOptions())
+	if err != nil {
+		return nil, err
+	}
+	c.changeLog.reset(applications)
+	return applications, nil
+}
+
+// reRegisterDue 连续失败 failures 次后是否需要重新注册，每连续失败 Config.ReRegisterAfterFailures 次重新注册一次
+func (c *Client) reRegisterDue(failures int) bool {
+	n := c.Config.ReRegisterAfterFailures
+	return n > 0 && failures%n == 0
+}
+
+// reRegister 心跳连续失败后执行完整的重新注册：尽力注销服务端可能残留的实例，更新 lastDirtyTimestamp 后注册
+func (c *Client) reRegister(failures int) error {
+	interval, _ := c.intervals()
+	ctx, cancel := context.WithTimeout(c.ctx, interval)
+	if err := c.doUnRegister(ctx); err != nil {
+		c.opLogger(OpUnRegister).Debugf("de-register before re-register failed: %v", err)
+	}
+	cancel()
+	c.Instance.setDirty(c.Config.clock().Now())
+	err := c.doRegister()
+	if err == nil {
+		c.opLogger(OpRegister).Info("re-register application instance successful")
+	} else {
+		c.opLogger(OpRegister).Error("re-register application instance failed", err)
+	}
+	c.stats.reRegistered()
+	if metrics, ok := c.metrics.(ReRegisterMetrics); ok {
+		metrics.ReRegistered(failures, err)
+	}
+	return err
+}
+
+// leaseRemaining 距离需要提前重新注册的剩余时间，即租约过期前一个心跳间隔，renewedAt 为零值时返回 0
+func (c *Client) leaseRemaining(renewedAt time.Time) time.Duration {
+	if renewedAt.IsZero() {
+		return 0
+	}
+	interval, _ := c.intervals()
+	lease := time.Duration(c.Config.DurationInSecs)*time.Second - interval
+	return lease - c.Config.clock().Now().Sub(renewedAt)
+}
+
+// leaseExpiring 自最近一次成功续约以来租约是否即将过期
+func (c *Client) leaseExpiring(renewedAt time.Time) bool {
+	return !renewedAt.IsZero() && c.leaseRemaining(renewedAt) <= 0
+}
+
+// updateDegraded 根据心跳（或重新注册）的结果更新连续失败次数，首次失败时进入降级模式，成功后退出
+func (c *Client) updateDegraded(failures int, err error) int {
+	if err == nil {
+		if failures > 0 {
+			c.stats.setDegraded(false)
+			c.opLogger(OpHeartbeat).Infof("heartbeat recovered after %d failures, leave degraded mode", failures)
+		}
+		return 0
+	}
+	if failures == 0 {
+		c.stats.setDegraded(true)
+		c.opLogger(OpHeartbeat).Warn("heartbeat failed, enter degraded mode with exponential backoff", err)
+	}
+	return failures + 1
+}
+
+// recordHeartbeat 记录心跳结果
+func (c *Client) recordHeartbeat(err error) {
+	c.stats.heartbeat(err, c.Config.clock().Now())
+	c.metrics.HeartbeatCompleted(err)
+}
+
+// recordFetch 记录拉取服务列表结果
+func (c *Client) recordFetch(apps *Applications, duration time.Duration, err error) {
+	c.stats.fetch(apps, err, c.Config.clock().Now())
+	c.metrics.RegistryFetched(apps, duration, err)
+}
+
+// handleSignal 监听退出信号，执行钩子并删除注册的实例后退出进程，客户端停止时不再监听
 func (c *Client) handleSignal() {
 	if c.signalChan == nil {
-		c.signalChan = make(chan os.Signal)
-	}
-	signal.Notify(c.signalChan, syscall.SIGTERM, syscall.SIGINT)
-	for c.running {
-		switch <-c.signalChan {
-		case syscall.SIGINT:
-			fallthrough
-		case syscall.SIGTERM:
-			c.logger.Info("receive exit signal, client instance going to de-register")
-			err := c.doUnRegister()
-			if err != nil {
-				c.logger.Error("de-register application instance failed", err)
-			} else {
-				c.logger.Info("de-register application instance successful")
-			}
-			os.Exit(0)
+		c.signalChan = make(chan os.Signal, 1)
+	}
+	signal.Notify(c.signalChan, c.Config.shutdownSignals()...)
+	defer signal.Stop(c.signalChan)
+	for {
+		var sig os.Signal
+		select {
+		case <-c.stop:
+			return
+		case sig = <-c.signalChan:
 		}
+		c.logger.Infof("receive exit signal %s, client instance going to de-register", sig)
+		c.runShutdownHooks(c.beforeHooks(), sig)
+		_ = c.Deregister()
+		c.runShutdownHooks(c.afterHooks(), sig)
+		os.Exit(0)
 	}
 }
 
-// NewClient 创建客户端
+// NewClient 创建客户端，配置有误时使用默认值继续，需要校验配置时使用 NewClientE
 func NewClient(config *Config, opts ...Option) *Client {
 	DefaultConfig(config)
-	instance := NewInstance(config)
+	instance := NewRegisteredInstance(config)
+	instance.heartbeatByClient = true
+	ctx, cancel := context.WithCancel(context.Background())
 	client := &Client{
-		logger:   NewLogger(),
-		Config:   config,
-		Instance: instance,
+		logger:          newClientLogger(config),
+		metrics:         nopMetrics{},
+		Config:          config,
+		Instance:        instance,
+		heartbeatWakeup: make(chan struct{}, 1),
+		refreshWakeup:   make(chan struct{}, 1),
+		stop:            make(chan struct{}),
+		ctx:             ctx,
+		cancel:          cancel,
 	}
+	client.resolver = NewResolver(client)
 	for _, opt := range opts {
-		opt(client.Instance)
+		opt(client.Instance.Instance)
 	}
+	client.SetLogger(client.logger)
 	return client
 }
 
@@ -193,37 +480,111 @@ func DefaultConfig(config *Config) {
 	if config.DurationInSecs == 0 {
 		config.DurationInSecs = 90
 	}
+	if config.Region == "" {
+		config.Region = DefaultRegion
+	}
+	if config.ContentType == "" {
+		config.ContentType = ContentTypeJSON
+	}
+	if config.HeartbeatBackoffBound == 0 {
+		config.HeartbeatBackoffBound = 10
+	}
+	if config.Clock == nil {
+		config.Clock = SystemClock
+	}
+	if config.TombstoneWindowSecs == 0 {
+		config.TombstoneWindowSecs = 300
+	}
 	if config.App == "" {
 		config.App = "unknown"
+	}
+	if config.NameMapper != nil {
+		config.App = config.NameMapper(config.App)
 	} else {
 		config.App = strings.ToLower(config.App)
 	}
 	if config.IP == "" {
-		config.IP = GetLocalIP()
+		ip, err := detectIP(config)
+		if err != nil {
+			ip = GetLocalIP()
+		}
+		config.IP = ip
 	}
 	if config.HostName == "" {
-		config.HostName = config.IP
+		if config.PreferIPAddress {
+			config.HostName = config.IP
+		} else {
+			config.HostName = resolveHostName(config.IP)
+		}
 	}
 	if config.Port == 0 {
 		config.Port = 80
 	}
+	if config.SecurePort == 0 {
+		config.SecurePort = 443
+	}
 	if config.InstanceID == "" {
 		config.InstanceID = fmt.Sprintf("%s:%s:%d", config.App, config.IP, config.Port)
 	}
 }
 
-// GetApplicationInstance 根据服务名获取注册的服务实例列表
+// GetApplicationInstance 根据服务名获取注册的服务实例列表，返回的实例为深拷贝
 func (c *Client) GetApplicationInstance(name string) []Instance {
+	c.mutex.RLock()
+	instances := c.findInstances(c.Applications, name)
+	c.mutex.RUnlock()
+
+	return instances
+}
+
+// ResolvedApps 同一代服务列表中多个应用的服务实例
+type ResolvedApps struct {
+	// 服务列表的代数，每次更新服务列表后递增
+	Generation uint64
+	// 应用名称（与查询时一致）对应的服务实例列表
+	Instances map[string][]Instance
+}
+
+// ResolveMany 从同一代服务列表中获取多个应用的服务实例，避免后台刷新时得到新旧混合的结果
+func (c *Client) ResolveMany(names []string) ResolvedApps {
+	c.mutex.RLock()
+	apps, generation := c.Applications, c.generation
+	c.mutex.RUnlock()
+
+	resolved := ResolvedApps{
+		Generation: generation,
+		Instances:  make(map[string][]Instance, len(names)),
+	}
+	for _, name := range names {
+		resolved.Instances[name] = c.findInstances(apps, name)
+	}
+	return resolved
+}
+
+// findInstances 从服务列表中查找应用的服务实例，返回深拷贝
+func (c *Client) findInstances(apps *Applications, name string) []Instance {
 	instances := make([]Instance, 0)
-	c.mutex.Lock()
-	if c.Applications != nil {
-		for _, app := range c.Applications.Applications {
-			if app.Name == name {
-				instances = append(instances, app.Instances...)
+	if apps != nil {
+		for _, app := range apps.Applications {
+			if c.mapName(app.Name) == c.mapName(name) {
+				for i := range app.Instances {
+					instances = append(instances, app.Instances[i].deepCopy())
+				}
 			}
 		}
 	}
-	c.mutex.Unlock()
-
 	return instances
 }
+
+// opLogger 返回附加了 operation 字段的日志实现
+func (c *Client) opLogger(op string) LoggerV2 {
+	return c.logger.WithFields(Fields{"operation": op})
+}
+
+// mapName 使用 NameMapper 转换应用名称
+func (c *Client) mapName(name string) string {
+	if c.Config.NameMapper == nil {
+		return name
+	}
+	return c.Config.NameMapper(name)
+}
@@ -0,0 +1,70 @@
+package eureka_client_test
+
+import (
+	"testing"
+	"time"
+
+	eureka "github.com/godoes/eureka-client"
+	"github.com/godoes/eureka-client/eurekatest"
+)
+
+func newTestInstance(id, ip string) eureka.Instance {
+	return eureka.Instance{
+		App:        "SVC",
+		InstanceID: id,
+		IPAddr:     ip,
+		Status:     eureka.StatusUp,
+		Port:       &eureka.Port{Port: 8080, Enabled: "true"},
+	}
+}
+
+func TestRefreshNowReplacedInstance(t *testing.T) {
+	tests := []struct {
+		name         string
+		disableDelta bool
+	}{
+		{"delta", false},
+		{"full", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := eurekatest.NewServer()
+			defer srv.Close()
+			srv.Register(newTestInstance("old", "10.0.0.1"))
+
+			c := eureka.NewClient(&eureka.Config{DefaultZone: srv.Zone(), App: "me", Port: 1, DisableDelta: tt.disableDelta})
+			if _, err := c.RefreshNow(); err != nil {
+				t.Fatal(err)
+			}
+
+			// 剔除一个实例并注册另一个状态相同的实例，apps hashcode 不变
+			srv.Evict("SVC", "old")
+			srv.Register(newTestInstance("new", "10.0.0.2"))
+			if _, err := c.RefreshNow(); err != nil {
+				t.Fatal(err)
+			}
+
+			instances := c.GetApplicationInstance("SVC")
+			if len(instances) != 1 || instances[0].InstanceID != "new" || instances[0].IPAddr != "10.0.0.2" {
+				t.Fatalf("instances = %+v, want only new 10.0.0.2", instances)
+			}
+		})
+	}
+}
+
+func TestClientHeartbeatsOncePerInterval(t *testing.T) {
+	srv := eurekatest.NewServer()
+	defer srv.Close()
+	c := eureka.NewClient(&eureka.Config{DefaultZone: srv.Zone(), App: "hb", Port: 8080, RenewalIntervalInSecs: 1})
+	c.Start()
+	defer c.Stop()
+	if !srv.WaitRegistered("HB", c.Config.InstanceID, 3*time.Second) {
+		t.Fatal("instance not registered")
+	}
+
+	time.Sleep(2500 * time.Millisecond)
+	// 心跳间隔为 1 秒，心跳循环与 BeatReactor 同时发送心跳时会超过 3 次
+	if n := srv.Heartbeats("HB", c.Config.InstanceID); n > 3 {
+		t.Fatalf("heartbeats = %d in 2.5s, want at most 3", n)
+	}
+}
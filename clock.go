@@ -0,0 +1,49 @@
+package eureka_client
+
+import (
+	"strconv"
+	"time"
+)
+
+// Clock 时间源，客户端的心跳记录、续约统计、lastDirtyTimestamp 等时间都从 Clock 获取，便于测试时使用确定的时间
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock 系统时间
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock 系统时间，Config.Clock 的默认值
+var SystemClock Clock = systemClock{}
+
+// timestampMillis 将时间格式化为 eureka 使用的毫秒时间戳
+func timestampMillis(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
+
+// setDirty 实例信息发生变化时同时更新 lastDirtyTimestamp 与 lastUpdatedTimestamp
+// 服务端通过 lastDirtyTimestamp 判断两边的实例信息哪个更新
+func (i *Instance) setDirty(now time.Time) {
+	mutex := i.stateLock()
+	mutex.Lock()
+	i.setDirtyLocked(now)
+	mutex.Unlock()
+}
+
+// setDirtyLocked 与 setDirty 相同，调用方已持有实例的状态锁
+func (i *Instance) setDirtyLocked(now time.Time) {
+	i.LastDirtyTimestamp = timestampMillis(now)
+	i.LastUpdatedTimestamp = i.LastDirtyTimestamp
+}
+
+// clock 获取配置的时间源，未配置则使用 SystemClock
+func (c *Config) clock() Clock {
+	if c.Clock == nil {
+		return SystemClock
+	}
+	return c.Clock
+}
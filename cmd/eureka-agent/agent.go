@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+// agent 代理注册本地进程，所有实例共用一个 BeatReactor 发送心跳
+type agent struct {
+	zone   string
+	beater *eureka.BeatReactor
+	logger eureka.LoggerV2
+	// 需要注册的实例
+	services []*service
+}
+
+// service 一个本地进程对应的实例
+type service struct {
+	instance    *eureka.RegisteredInstance
+	healthCheck healthCheckConfig
+	logger      eureka.LoggerV2
+	registered  bool
+}
+
+func newAgent(config *agentConfig, logger eureka.LoggerV2) *agent {
+	base := &eureka.Config{
+		DefaultZone:           config.Eureka.DefaultZone,
+		RenewalIntervalInSecs: config.Eureka.RenewalIntervalInSecs,
+		DurationInSecs:        config.Eureka.DurationInSecs,
+		BeatWorkers:           config.Eureka.BeatWorkers,
+	}
+	eureka.DefaultConfig(base)
+	beater := eureka.NewBeatReactor(base, int64(base.RenewalIntervalInSecs))
+	beater.SetLogger(logger.WithFields(eureka.Fields{"component": "beat"}))
+
+	a := &agent{
+		zone:   base.DefaultZone,
+		beater: &beater,
+		logger: logger,
+	}
+	for _, sc := range config.Services {
+		c := &eureka.Config{
+			DefaultZone:           base.DefaultZone,
+			RenewalIntervalInSecs: sc.RenewalIntervalInSecs,
+			DurationInSecs:        sc.DurationInSecs,
+			InstanceID:            sc.InstanceID,
+			App:                   sc.App,
+			Version:               sc.Version,
+			HostName:              sc.HostName,
+			IP:                    sc.IP,
+			NetworkInterface:      sc.NetworkInterface,
+			PreferredNetworks:     sc.PreferredNetworks,
+			PreferIPAddress:       sc.PreferIPAddress,
+			Port:                  sc.Port,
+			SecurePort:            sc.SecurePort,
+			SecurePortEnabled:     sc.SecurePortEnabled,
+			Metadata:              sc.Metadata,
+		}
+		if c.RenewalIntervalInSecs == 0 {
+			c.RenewalIntervalInSecs = base.RenewalIntervalInSecs
+		}
+		if c.DurationInSecs == 0 {
+			c.DurationInSecs = base.DurationInSecs
+		}
+		eureka.DefaultConfig(c)
+		instance := &eureka.RegisteredInstance{
+			Instance: eureka.NewInstance(c),
+			Config:   c,
+			Beater:   a.beater,
+		}
+		a.services = append(a.services, &service{
+			instance:    instance,
+			healthCheck: sc.HealthCheck,
+			logger:      logger.WithFields(eureka.Fields{"app": instance.App, "instanceId": instance.InstanceID}),
+		})
+	}
+	return a
+}
+
+// run 运行所有实例的健康检查，ctx 结束后注销已注册的实例并停止心跳
+func (a *agent) run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, s := range a.services {
+		wg.Add(1)
+		go func(s *service) {
+			defer wg.Done()
+			a.watch(ctx, s)
+		}(s)
+	}
+	wg.Wait()
+	a.beater.Stop()
+}
+
+// watch 定时检查实例健康状态，健康时注册（心跳停止后重新注册），连续失败达到阈值后注销
+func (a *agent) watch(ctx context.Context, s *service) {
+	failures := 0
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if s.registered {
+				a.unregister(s)
+			}
+			return
+		case <-timer.C:
+		}
+
+		if err := s.healthCheck.check(ctx); err != nil {
+			failures++
+			s.logger.Warn(fmt.Sprintf("health check failed (%d/%d)", failures, s.healthCheck.FailureThreshold), err)
+			if s.registered && failures >= s.healthCheck.FailureThreshold {
+				a.unregister(s)
+			}
+		} else {
+			failures = 0
+			if !s.registered || !a.beater.Beating(s.instance.InstanceID) {
+				a.register(s)
+			}
+		}
+		timer.Reset(s.healthCheck.Interval)
+	}
+}
+
+func (a *agent) register(s *service) {
+	if err := s.instance.Register(); err != nil {
+		s.logger.Error("register instance failed", err)
+		return
+	}
+	s.registered = true
+	s.logger.Info("register instance successful")
+}
+
+func (a *agent) unregister(s *service) {
+	if err := s.instance.UnRegister(); err != nil {
+		s.logger.Error("de-register instance failed", err)
+		return
+	}
+	s.registered = false
+	s.logger.Info("de-register instance successful")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	eureka "github.com/godoes/eureka-client"
+	"gopkg.in/yaml.v3"
+)
+
+// agentConfig 代理配置
+type agentConfig struct {
+	Eureka   eurekaConfig    `yaml:"eureka"`
+	Services []serviceConfig `yaml:"services"`
+}
+
+// eurekaConfig eureka 服务端配置，所有服务共用
+type eurekaConfig struct {
+	// eureka 服务端地址
+	DefaultZone string `yaml:"defaultZone"`
+	// 心跳间隔，默认 30s
+	RenewalIntervalInSecs int `yaml:"renewalIntervalInSecs"`
+	// 过期间隔，默认 90s
+	DurationInSecs int `yaml:"durationInSecs"`
+	// 同时发送心跳的工作 goroutine 上限，默认 20
+	BeatWorkers int `yaml:"beatWorkers"`
+}
+
+// serviceConfig 需要注册的本地进程
+type serviceConfig struct {
+	App        string                 `yaml:"app"`
+	InstanceID string                 `yaml:"instanceId"`
+	HostName   string                 `yaml:"hostName"`
+	IP         string                 `yaml:"ip"`
+	Port       int                    `yaml:"port"`
+	Version    string                 `yaml:"version"`
+	Metadata   map[string]interface{} `yaml:"metadata"`
+	// 未配置 ip 时取该网卡的地址，例如 eth1
+	NetworkInterface string `yaml:"networkInterface"`
+	// 未配置 ip 时优先选择的网段，例如 10.2.0.0/16
+	PreferredNetworks []string `yaml:"preferredNetworks"`
+	// 未配置 hostName 时使用 ip 作为主机名
+	PreferIPAddress bool `yaml:"preferIpAddress"`
+	// https 端口，securePortEnabled 为 true 时注册 https 的实例地址
+	SecurePort        int  `yaml:"securePort"`
+	SecurePortEnabled bool `yaml:"securePortEnabled"`
+	// 该实例的心跳间隔与过期间隔，未设置则使用 eureka 中的配置
+	RenewalIntervalInSecs int `yaml:"renewalIntervalInSecs"`
+	DurationInSecs        int `yaml:"durationInSecs"`
+	// 健康检查，未配置则认为一直健康
+	HealthCheck healthCheckConfig `yaml:"healthCheck"`
+}
+
+// healthCheckConfig 健康检查配置，http 与 tcp 二选一
+type healthCheckConfig struct {
+	// http 检查地址，响应 2xx 为健康
+	HTTP string `yaml:"http"`
+	// tcp 检查地址（host:port），能建立连接为健康
+	TCP string `yaml:"tcp"`
+	// 检查间隔，默认 10s
+	Interval time.Duration `yaml:"interval"`
+	// 检查超时，默认 2s
+	Timeout time.Duration `yaml:"timeout"`
+	// 连续失败多少次后注销实例，默认 3
+	FailureThreshold int `yaml:"failureThreshold"`
+}
+
+// loadConfig 读取并校验 YAML 配置文件
+func loadConfig(path string) (*agentConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := new(agentConfig)
+	if err = yaml.Unmarshal(b, config); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(config.Services) == 0 {
+		return nil, errors.New("no services configured")
+	}
+	for i := range config.Services {
+		service := &config.Services[i]
+		if service.App == "" {
+			return nil, fmt.Errorf("services[%d]: app is required", i)
+		}
+		if service.Port == 0 {
+			return nil, fmt.Errorf("services[%d]: port is required", i)
+		}
+		if _, err = eureka.ConvertMetadata(service.Metadata); err != nil {
+			return nil, fmt.Errorf("services[%d]: %w", i, err)
+		}
+		if service.HealthCheck.HTTP != "" && service.HealthCheck.TCP != "" {
+			return nil, fmt.Errorf("services[%d]: only one of healthCheck.http and healthCheck.tcp can be set", i)
+		}
+		if service.HealthCheck.Interval <= 0 {
+			service.HealthCheck.Interval = 10 * time.Second
+		}
+		if service.HealthCheck.Timeout <= 0 {
+			service.HealthCheck.Timeout = 2 * time.Second
+		}
+		if service.HealthCheck.FailureThreshold <= 0 {
+			service.HealthCheck.FailureThreshold = 3
+		}
+	}
+	return config, nil
+}
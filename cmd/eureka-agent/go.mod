@@ -0,0 +1,10 @@
+module github.com/godoes/eureka-client/cmd/eureka-agent
+
+go 1.18
+
+require (
+	github.com/godoes/eureka-client v0.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/godoes/eureka-client => ../../
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// check 执行一次健康检查，健康时返回 nil
+func (c healthCheckConfig) check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+	switch {
+	case c.HTTP != "":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.HTTP, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			return fmt.Errorf("health check %s returned status %d", c.HTTP, resp.StatusCode)
+		}
+		return nil
+	case c.TCP != "":
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", c.TCP)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,35 @@
+// eureka-agent 将本地的非 Go 进程注册到 eureka，并负责健康检查与心跳
+//
+//	eureka-agent -config agent.yaml
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+func main() {
+	path := flag.String("config", "agent.yaml", "path of the YAML config file")
+	flag.Parse()
+
+	config, err := loadConfig(*path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "eureka-agent:", err)
+		os.Exit(1)
+	}
+
+	logger := eureka.NewDefaultLogger(eureka.WithTimestamp(true)).WithFields(eureka.Fields{"component": "agent"})
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	a := newAgent(config, logger)
+	logger.Infof("managing %d services, eureka server: %s", len(a.services), eureka.RedactURL(a.zone))
+	a.run(ctx)
+	logger.Info("stopped")
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+// normalizeZone 服务端地址以 / 结尾
+func normalizeZone(zone string) string {
+	if !strings.HasSuffix(zone, "/") {
+		zone += "/"
+	}
+	return zone
+}
+
+// newFlagSet 创建子命令的参数解析
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: eureka-cli %s %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// instanceFlags 定位实例的参数
+func instanceFlags(fs *flag.FlagSet) (app, id *string) {
+	app = fs.String("app", "", "application name (required)")
+	id = fs.String("id", "", "instance id (required)")
+	return app, id
+}
+
+// requireInstance 检查实例参数
+func requireInstance(app, id string) error {
+	if app == "" || id == "" {
+		return errors.New("-app and -id are required")
+	}
+	return nil
+}
+
+// runApps 列出应用与实例数量
+func runApps(zone string, args []string) error {
+	fs := newFlagSet("apps", "[-json]")
+	asJSON := fs.Bool("json", false, "print the raw registry as JSON")
+	_ = fs.Parse(args)
+
+	apps, err := eureka.Refresh(zone)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(apps)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "APP\tINSTANCES\tUP")
+	for _, app := range apps.Applications {
+		up := 0
+		for _, instance := range app.Instances {
+			if instance.Status == "UP" {
+				up++
+			}
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\n", app.Name, len(app.Instances), up)
+	}
+	return w.Flush()
+}
+
+// runInstances 列出应用的实例
+func runInstances(zone string, args []string) error {
+	fs := newFlagSet("instances", "[-json] <app>")
+	asJSON := fs.Bool("json", false, "print the instances as JSON")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("application name is required")
+	}
+
+	apps, err := eureka.Refresh(zone)
+	if err != nil {
+		return err
+	}
+	var instances []eureka.Instance
+	for _, app := range apps.Applications {
+		if strings.EqualFold(app.Name, fs.Arg(0)) {
+			instances = append(instances, app.Instances...)
+		}
+	}
+	if *asJSON {
+		return printJSON(instances)
+	}
+	if len(instances) == 0 {
+		return fmt.Errorf("no instances of %s", fs.Arg(0))
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "INSTANCE ID\tSTATUS\tOVERRIDDEN\tADDRESS\tSECURE PORT")
+	for _, instance := range instances {
+		secure := "-"
+		if instance.SecurePort.IsEnabled() {
+			secure = fmt.Sprint(instance.SecurePort.Int())
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s:%d\t%s\n", instance.InstanceID, instance.Status, instance.OverriddenStatus,
+			instance.IPAddr, instance.Port.Int(), secure)
+	}
+	return w.Flush()
+}
+
+// runRegister 注册一个合成的实例
+func runRegister(zone string, args []string) error {
+	fs := newFlagSet("register", "-app name [-id id] [-ip ip] [-port port] [-meta k=v,...]")
+	app := fs.String("app", "", "application name (required)")
+	id := fs.String("id", "", "instance id, defaults to app:ip:port")
+	ip := fs.String("ip", "", "instance IP, defaults to the local IP")
+	hostName := fs.String("hostname", "", "instance host name, defaults to the IP")
+	port := fs.Int("port", 80, "instance port")
+	lease := fs.Int("lease", 90, "lease duration in seconds")
+	meta := fs.String("meta", "", "comma separated metadata, e.g. version=1.0,zone=a")
+	_ = fs.Parse(args)
+	if *app == "" {
+		return errors.New("-app is required")
+	}
+
+	config := &eureka.Config{
+		DefaultZone:      zone,
+		App:              *app,
+		InstanceID:       *id,
+		IP:               *ip,
+		HostName:         *hostName,
+		Port:             *port,
+		DurationInSecs:   *lease,
+		InstanceMetadata: parseMetadata(*meta),
+	}
+	eureka.DefaultConfig(config)
+	instance := eureka.NewInstance(config)
+	if err := eureka.RegisterOnly(zone, config.App, instance); err != nil {
+		return err
+	}
+	fmt.Printf("registered %s of %s, send heartbeats within %ds to keep it\n", instance.InstanceID, config.App, *lease)
+	return nil
+}
+
+// runHeartbeat 发送实例的心跳
+func runHeartbeat(zone string, args []string) error {
+	fs := newFlagSet("heartbeat", "-app name -id id [-count n] [-interval 30s]")
+	app, id := instanceFlags(fs)
+	count := fs.Int("count", 1, "number of heartbeats to send, 0 to send until interrupted")
+	interval := fs.Duration("interval", 30*time.Second, "interval between heartbeats")
+	_ = fs.Parse(args)
+	if err := requireInstance(*app, *id); err != nil {
+		return err
+	}
+
+	for i := 0; *count == 0 || i < *count; i++ {
+		if i > 0 {
+			time.Sleep(*interval)
+		}
+		if err := eureka.Heartbeat(zone, *app, *id); err != nil {
+			if err == eureka.ErrNotFound {
+				return fmt.Errorf("instance %s of %s is not registered", *id, *app)
+			}
+			return err
+		}
+		fmt.Printf("%s heartbeat %s ok\n", time.Now().Format(time.RFC3339), *id)
+	}
+	return nil
+}
+
+// runStatus 覆盖实例的状态
+func runStatus(zone string, args []string) error {
+	fs := newFlagSet("status", "-app name -id id -value OUT_OF_SERVICE")
+	app, id := instanceFlags(fs)
+	value := fs.String("value", "OUT_OF_SERVICE", "status to override with, e.g. UP, DOWN, OUT_OF_SERVICE")
+	_ = fs.Parse(args)
+	if err := requireInstance(*app, *id); err != nil {
+		return err
+	}
+
+	if err := eureka.StatusOverride(zone, *app, *id, strings.ToUpper(*value)); err != nil {
+		return err
+	}
+	fmt.Printf("status of %s overridden with %s\n", *id, strings.ToUpper(*value))
+	return nil
+}
+
+// runDeregister 注销实例
+func runDeregister(zone string, args []string) error {
+	fs := newFlagSet("deregister", "-app name -id id")
+	app, id := instanceFlags(fs)
+	_ = fs.Parse(args)
+	if err := requireInstance(*app, *id); err != nil {
+		return err
+	}
+
+	if err := eureka.UnRegister(zone, *app, &eureka.Instance{InstanceID: *id}); err != nil {
+		return err
+	}
+	fmt.Printf("de-registered %s of %s\n", *id, *app)
+	return nil
+}
+
+// parseMetadata 解析 k=v,k=v 格式的元数据
+func parseMetadata(s string) eureka.Metadata {
+	metadata := make(eureka.Metadata)
+	for _, pair := range strings.Split(s, ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok && k != "" {
+			metadata[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return metadata
+}
+
+// printJSON 以缩进的 JSON 输出
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
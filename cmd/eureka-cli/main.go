@@ -0,0 +1,70 @@
+// eureka-cli 在命令行中查看与操作 eureka 服务端，用于排查 eureka 环境的问题
+//
+//	eureka-cli [-zone http://localhost:8761/eureka/] <command> [flags]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// envZone 未指定 -zone 时读取的环境变量
+const envZone = "EUREKA_ZONE"
+
+// command 子命令
+type command struct {
+	usage string
+	run   func(zone string, args []string) error
+}
+
+var commands = map[string]command{
+	"apps":       {"list applications and their instance counts", runApps},
+	"instances":  {"list instances of an application", runInstances},
+	"register":   {"register a synthetic instance", runRegister},
+	"heartbeat":  {"send heartbeats for an instance", runHeartbeat},
+	"status":     {"override the status of an instance, e.g. OUT_OF_SERVICE", runStatus},
+	"deregister": {"de-register an instance", runDeregister},
+}
+
+func main() {
+	zone := os.Getenv(envZone)
+	if zone == "" {
+		zone = "http://localhost:8761/eureka/"
+	}
+	flag.StringVar(&zone, "zone", zone, "eureka server address, defaults to $"+envZone)
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "eureka-cli: unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(normalizeZone(zone), flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "eureka-cli:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: eureka-cli [-zone url] <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-11s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nrun 'eureka-cli <command> -h' for the flags of a command")
+	fmt.Fprintln(os.Stderr, "\nglobal flags:")
+	flag.PrintDefaults()
+}
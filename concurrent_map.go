@@ -2,36 +2,103 @@ package eureka_client
 
 import (
 	"encoding/json"
+	"errors"
 	"sync"
 )
 
+// ShardCount The default number of shards for maps created afterwards.
+// Every map stores its own shard count at construction, changing ShardCount does not affect existing maps.
 var ShardCount = 32
 
 // ConcurrentMap A "thread" safe map of type string:Anything.
 // To avoid lock bottlenecks this map is dived to several (ShardCount) map shards.
-type ConcurrentMap []*ConcurrentMapShared
+// It is kept for compatibility, new code should use ConcurrentMapOf.
+type ConcurrentMap = ConcurrentMapOf[string, interface{}]
 
 // ConcurrentMapShared A "thread" safe string to anything map.
-type ConcurrentMapShared struct {
-	items        map[string]interface{}
+type ConcurrentMapShared = ConcurrentMapSharedOf[string, interface{}]
+
+// Tuple Used by the Iter & IterBuffered functions to wrap two variables together over a channel,
+type Tuple = TupleOf[string, interface{}]
+
+// UpsertCb Callback to return new element to be inserted into the map, see UpsertCbOf.
+type UpsertCb = UpsertCbOf[interface{}]
+
+// IterCb Iterator callback, see IterCbOf.
+type IterCb = IterCbOf[string, interface{}]
+
+// RemoveCb Callback to decide whether an element should be removed, see RemoveCbOf.
+type RemoveCb = RemoveCbOf[string, interface{}]
+
+// ConcurrentMapOf A "thread" safe map of type K:V.
+// To avoid lock bottlenecks this map is dived to several map shards (ShardCount by default),
+// a key is assigned to a shard by the sharding function.
+type ConcurrentMapOf[K comparable, V any] struct {
+	shards   []*ConcurrentMapSharedOf[K, V]
+	sharding func(key K) uint32
+}
+
+// ConcurrentMapSharedOf A "thread" safe K to V map.
+type ConcurrentMapSharedOf[K comparable, V any] struct {
+	items        map[K]V
 	sync.RWMutex // Read Write mutex, guards access to internal map.
 }
 
 // NewConcurrentMap Creates a new concurrent map.
 func NewConcurrentMap() ConcurrentMap {
-	m := make(ConcurrentMap, ShardCount)
-	for i := 0; i < ShardCount; i++ {
-		m[i] = &ConcurrentMapShared{items: make(map[string]interface{})}
+	return NewConcurrentMapOf[interface{}]()
+}
+
+// NewConcurrentMapWithShards Creates a new concurrent map with n shards, ShardCount is used if n <= 0.
+func NewConcurrentMapWithShards(n int) ConcurrentMap {
+	return NewConcurrentMapOfWithShards[interface{}](n)
+}
+
+// NewConcurrentMapOf Creates a new concurrent map with string keys.
+func NewConcurrentMapOf[V any]() ConcurrentMapOf[string, V] {
+	return NewConcurrentMapOfWithShards[V](0)
+}
+
+// NewConcurrentMapOfWithShards Creates a new concurrent map with string keys and n shards, ShardCount is used if n <= 0.
+func NewConcurrentMapOfWithShards[V any](n int) ConcurrentMapOf[string, V] {
+	return newConcurrentMapOf[string, V](n, fnv32)
+}
+
+// NewConcurrentMapOfSharding Creates a new concurrent map with keys of any comparable type,
+// sharding returns the hash of a key that decides which shard the key belongs to.
+func NewConcurrentMapOfSharding[K comparable, V any](sharding func(key K) uint32) ConcurrentMapOf[K, V] {
+	return newConcurrentMapOf[K, V](0, sharding)
+}
+
+// newConcurrentMapOf Creates a new concurrent map with n shards, ShardCount is used if n <= 0.
+func newConcurrentMapOf[K comparable, V any](n int, sharding func(key K) uint32) ConcurrentMapOf[K, V] {
+	if n <= 0 {
+		n = ShardCount
+	}
+	if n <= 0 {
+		n = 1
+	}
+	m := ConcurrentMapOf[K, V]{
+		shards:   make([]*ConcurrentMapSharedOf[K, V], n),
+		sharding: sharding,
+	}
+	for i := range m.shards {
+		m.shards[i] = &ConcurrentMapSharedOf[K, V]{items: make(map[K]V)}
 	}
 	return m
 }
 
+// ShardCount Returns the number of shards of the map, fixed at construction.
+func (m ConcurrentMapOf[K, V]) ShardCount() int {
+	return len(m.shards)
+}
+
 // GetShard Returns shard under given key
-func (m ConcurrentMap) GetShard(key string) *ConcurrentMapShared {
-	return m[uint(fnv32(key))%uint(ShardCount)]
+func (m ConcurrentMapOf[K, V]) GetShard(key K) *ConcurrentMapSharedOf[K, V] {
+	return m.shards[uint(m.sharding(key))%uint(len(m.shards))]
 }
 
-func (m ConcurrentMap) MSet(data map[string]interface{}) {
+func (m ConcurrentMapOf[K, V]) MSet(data map[K]V) {
 	for key, value := range data {
 		shard := m.GetShard(key)
 		shard.Lock()
@@ -41,7 +108,7 @@ func (m ConcurrentMap) MSet(data map[string]interface{}) {
 }
 
 // Set Sets the given value under the specified key.
-func (m ConcurrentMap) Set(key string, value interface{}) {
+func (m ConcurrentMapOf[K, V]) Set(key K, value V) {
 	// Get map shard.
 	shard := m.GetShard(key)
 	shard.Lock()
@@ -49,14 +116,14 @@ func (m ConcurrentMap) Set(key string, value interface{}) {
 	shard.Unlock()
 }
 
-// UpsertCb Callback to return new element to be inserted into the map
+// UpsertCbOf Callback to return new element to be inserted into the map
 // It is called while lock is held, therefore it MUST NOT
 // try to access other keys in same map, as it can lead to deadlock since
 // Go sync.RWLock is not reentrant
-type UpsertCb func(exist bool, valueInMap interface{}, newValue interface{}) interface{}
+type UpsertCbOf[V any] func(exist bool, valueInMap V, newValue V) V
 
 // Upsert Insert or Update - updates existing element or inserts a new one using UpsertCb
-func (m ConcurrentMap) Upsert(key string, value interface{}, cb UpsertCb) (res interface{}) {
+func (m ConcurrentMapOf[K, V]) Upsert(key K, value V, cb UpsertCbOf[V]) (res V) {
 	shard := m.GetShard(key)
 	shard.Lock()
 	v, ok := shard.items[key]
@@ -67,7 +134,7 @@ func (m ConcurrentMap) Upsert(key string, value interface{}, cb UpsertCb) (res i
 }
 
 // SetIfAbsent Sets the given value under the specified key if no value was associated with it.
-func (m ConcurrentMap) SetIfAbsent(key string, value interface{}) bool {
+func (m ConcurrentMapOf[K, V]) SetIfAbsent(key K, value V) bool {
 	// Get map shard.
 	shard := m.GetShard(key)
 	shard.Lock()
@@ -79,8 +146,23 @@ func (m ConcurrentMap) SetIfAbsent(key string, value interface{}) bool {
 	return !ok
 }
 
+// GetOrCompute Returns the existing value for the key if present.
+// Otherwise, it calls compute, stores and returns its result. loaded reports whether the value was present.
+// compute is called while the shard lock is held, therefore it MUST NOT access the same map.
+func (m ConcurrentMapOf[K, V]) GetOrCompute(key K, compute func() V) (actual V, loaded bool) {
+	shard := m.GetShard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	if v, ok := shard.items[key]; ok {
+		return v, true
+	}
+	actual = compute()
+	shard.items[key] = actual
+	return actual, false
+}
+
 // Get Retrieves an element from map under given key.
-func (m ConcurrentMap) Get(key string) (interface{}, bool) {
+func (m ConcurrentMapOf[K, V]) Get(key K) (V, bool) {
 	// Get shard
 	shard := m.GetShard(key)
 	shard.RLock()
@@ -91,10 +173,9 @@ func (m ConcurrentMap) Get(key string) (interface{}, bool) {
 }
 
 // Count Returns the number of elements within the map.
-func (m ConcurrentMap) Count() int {
+func (m ConcurrentMapOf[K, V]) Count() int {
 	count := 0
-	for i := 0; i < ShardCount; i++ {
-		shard := m[i]
+	for _, shard := range m.shards {
 		shard.RLock()
 		count += len(shard.items)
 		shard.RUnlock()
@@ -103,7 +184,7 @@ func (m ConcurrentMap) Count() int {
 }
 
 // Has Looks up an item under specified key
-func (m ConcurrentMap) Has(key string) bool {
+func (m ConcurrentMapOf[K, V]) Has(key K) bool {
 	// Get shard
 	shard := m.GetShard(key)
 	shard.RLock()
@@ -114,7 +195,7 @@ func (m ConcurrentMap) Has(key string) bool {
 }
 
 // Remove Removes an element from the map.
-func (m ConcurrentMap) Remove(key string) {
+func (m ConcurrentMapOf[K, V]) Remove(key K) {
 	// Try to get shard.
 	shard := m.GetShard(key)
 	shard.Lock()
@@ -122,8 +203,28 @@ func (m ConcurrentMap) Remove(key string) {
 	shard.Unlock()
 }
 
+// RemoveCbOf A callback executed in a map.RemoveCb() call, while Lock is held
+// If returns true, the element will be removed from the map
+type RemoveCbOf[K comparable, V any] func(key K, v V, exists bool) bool
+
+// RemoveCb Locks the shard containing the key, retrieves its current value and calls the callback with those params
+// If callback returns true and element exists, it will remove it from the map
+// Returns the value returned by the callback (even if element was not present in the map)
+func (m ConcurrentMapOf[K, V]) RemoveCb(key K, cb RemoveCbOf[K, V]) bool {
+	// Try to get shard.
+	shard := m.GetShard(key)
+	shard.Lock()
+	v, ok := shard.items[key]
+	remove := cb(key, v, ok)
+	if remove && ok {
+		delete(shard.items, key)
+	}
+	shard.Unlock()
+	return remove
+}
+
 // Pop PopRemoves an element from the map and returns it
-func (m ConcurrentMap) Pop(key string) (v interface{}, exists bool) {
+func (m ConcurrentMapOf[K, V]) Pop(key K) (v V, exists bool) {
 	// Try to get shard.
 	shard := m.GetShard(key)
 	shard.Lock()
@@ -133,35 +234,98 @@ func (m ConcurrentMap) Pop(key string) (v interface{}, exists bool) {
 	return v, exists
 }
 
+// Clear Removes all elements from the map and returns the number of removed elements.
+// All shards are locked during the operation, so no other goroutine observes a partially cleared map.
+func (m ConcurrentMapOf[K, V]) Clear() int {
+	for _, shard := range m.shards {
+		shard.Lock()
+	}
+	count := 0
+	for _, shard := range m.shards {
+		count += len(shard.items)
+		shard.items = make(map[K]V)
+	}
+	for _, shard := range m.shards {
+		shard.Unlock()
+	}
+	return count
+}
+
+// ConcurrentMapStats Per-shard sizes of a map, used to detect hash skew and memory hotspots.
+type ConcurrentMapStats struct {
+	// Number of shards
+	Shards int
+	// Number of elements in all shards
+	Count int
+	// Number of elements in the smallest and the largest shard
+	Min, Max int
+	// Average number of elements per shard
+	Mean float64
+	// Number of elements in each shard, indexed by shard
+	Sizes []int
+}
+
+// Skew Returns the ratio of the largest shard to the average shard size, 1 means perfectly balanced.
+// Returns 0 for an empty map.
+func (s ConcurrentMapStats) Skew() float64 {
+	if s.Mean == 0 {
+		return 0
+	}
+	return float64(s.Max) / s.Mean
+}
+
+// Stats Returns per-shard sizes of the map.
+// Shards are read one by one, the result is not a consistent snapshot while the map is being modified.
+func (m ConcurrentMapOf[K, V]) Stats() ConcurrentMapStats {
+	stats := ConcurrentMapStats{Shards: len(m.shards), Sizes: make([]int, len(m.shards))}
+	for i, shard := range m.shards {
+		shard.RLock()
+		size := len(shard.items)
+		shard.RUnlock()
+		stats.Sizes[i] = size
+		stats.Count += size
+		if i == 0 || size < stats.Min {
+			stats.Min = size
+		}
+		if size > stats.Max {
+			stats.Max = size
+		}
+	}
+	if stats.Shards > 0 {
+		stats.Mean = float64(stats.Count) / float64(stats.Shards)
+	}
+	return stats
+}
+
 // IsEmpty Checks if map is empty.
-func (m ConcurrentMap) IsEmpty() bool {
+func (m ConcurrentMapOf[K, V]) IsEmpty() bool {
 	return m.Count() == 0
 }
 
-// Tuple Used by the Iter & IterBuffered functions to wrap two variables together over a channel,
-type Tuple struct {
-	Key string
-	Val interface{}
+// TupleOf Used by the Iter & IterBuffered functions to wrap two variables together over a channel,
+type TupleOf[K comparable, V any] struct {
+	Key K
+	Val V
 }
 
 // Iter Returns an iterator which could be used in a for range loop.
 //
 // Deprecated: using IterBuffered() will get a better performance
-func (m ConcurrentMap) Iter() <-chan Tuple {
+func (m ConcurrentMapOf[K, V]) Iter() <-chan TupleOf[K, V] {
 	channels := snapshot(m)
-	ch := make(chan Tuple)
+	ch := make(chan TupleOf[K, V])
 	go fanIn(channels, ch)
 	return ch
 }
 
 // IterBuffered Returns a buffered iterator which could be used in a for range loop.
-func (m ConcurrentMap) IterBuffered() <-chan Tuple {
+func (m ConcurrentMapOf[K, V]) IterBuffered() <-chan TupleOf[K, V] {
 	channels := snapshot(m)
 	total := 0
 	for _, c := range channels {
 		total += cap(c)
 	}
-	ch := make(chan Tuple, total)
+	ch := make(chan TupleOf[K, V], total)
 	go fanIn(channels, ch)
 	return ch
 }
@@ -170,19 +334,19 @@ func (m ConcurrentMap) IterBuffered() <-chan Tuple {
 // which likely takes a snapshot of `m`.
 // It returns once the size of each buffered channel is determined,
 // before all the channels are populated using goroutines.
-func snapshot(m ConcurrentMap) (channels []chan Tuple) {
-	channels = make([]chan Tuple, ShardCount)
+func snapshot[K comparable, V any](m ConcurrentMapOf[K, V]) (channels []chan TupleOf[K, V]) {
+	channels = make([]chan TupleOf[K, V], len(m.shards))
 	wg := sync.WaitGroup{}
-	wg.Add(ShardCount)
+	wg.Add(len(m.shards))
 	// Foreach shard.
-	for index, shard := range m {
-		go func(index int, shard *ConcurrentMapShared) {
+	for index, shard := range m.shards {
+		go func(index int, shard *ConcurrentMapSharedOf[K, V]) {
 			// Foreach key, value pair.
 			shard.RLock()
-			channels[index] = make(chan Tuple, len(shard.items))
+			channels[index] = make(chan TupleOf[K, V], len(shard.items))
 			wg.Done()
 			for key, val := range shard.items {
-				channels[index] <- Tuple{key, val}
+				channels[index] <- TupleOf[K, V]{key, val}
 			}
 			shard.RUnlock()
 			close(channels[index])
@@ -193,11 +357,11 @@ func snapshot(m ConcurrentMap) (channels []chan Tuple) {
 }
 
 // fanIn reads elements from channels `channels` into channel `out`
-func fanIn(channels []chan Tuple, out chan Tuple) {
+func fanIn[K comparable, V any](channels []chan TupleOf[K, V], out chan TupleOf[K, V]) {
 	wg := sync.WaitGroup{}
 	wg.Add(len(channels))
 	for _, ch := range channels {
-		go func(ch chan Tuple) {
+		go func(ch chan TupleOf[K, V]) {
 			for t := range ch {
 				out <- t
 			}
@@ -208,9 +372,9 @@ func fanIn(channels []chan Tuple, out chan Tuple) {
 	close(out)
 }
 
-// Items Returns all items as map[string]interface{}
-func (m ConcurrentMap) Items() map[string]interface{} {
-	tmp := make(map[string]interface{})
+// Items Returns all items as map[K]V
+func (m ConcurrentMapOf[K, V]) Items() map[K]V {
+	tmp := make(map[K]V)
 
 	// Insert items to temporary map.
 	for item := range m.IterBuffered() {
@@ -220,17 +384,16 @@ func (m ConcurrentMap) Items() map[string]interface{} {
 	return tmp
 }
 
-// IterCb Iterator callback,called for every key,value found in
+// IterCbOf Iterator callback,called for every key,value found in
 // maps. RLock is held for all calls for a given shard
 // therefore callback sess consistent view of a shard,
 // but not across the shards
-type IterCb func(key string, v interface{})
+type IterCbOf[K comparable, V any] func(key K, v V)
 
 // IterCb Callback based iterator, cheapest way to read
 // all elements in a map.
-func (m ConcurrentMap) IterCb(fn IterCb) {
-	for idx := range m {
-		shard := (m)[idx]
+func (m ConcurrentMapOf[K, V]) IterCb(fn IterCbOf[K, V]) {
+	for _, shard := range m.shards {
 		shard.RLock()
 		for key, value := range shard.items {
 			fn(key, value)
@@ -239,16 +402,16 @@ func (m ConcurrentMap) IterCb(fn IterCb) {
 	}
 }
 
-// Keys Return all keys as []string
-func (m ConcurrentMap) Keys() []string {
+// Keys Return all keys as []K
+func (m ConcurrentMapOf[K, V]) Keys() []K {
 	count := m.Count()
-	ch := make(chan string, count)
+	ch := make(chan K, count)
 	go func() {
 		// Foreach shard.
 		wg := sync.WaitGroup{}
-		wg.Add(ShardCount)
-		for _, shard := range m {
-			go func(shard *ConcurrentMapShared) {
+		wg.Add(len(m.shards))
+		for _, shard := range m.shards {
+			go func(shard *ConcurrentMapSharedOf[K, V]) {
 				// Foreach key, value pair.
 				shard.RLock()
 				for key := range shard.items {
@@ -263,7 +426,7 @@ func (m ConcurrentMap) Keys() []string {
 	}()
 
 	// Generate keys
-	keys := make([]string, 0, count)
+	keys := make([]K, 0, count)
 	for k := range ch {
 		keys = append(keys, k)
 	}
@@ -271,9 +434,9 @@ func (m ConcurrentMap) Keys() []string {
 }
 
 // MarshalJSON Reviles ConcurrentMap "private" variables to json marshal.
-func (m ConcurrentMap) MarshalJSON() ([]byte, error) {
+func (m ConcurrentMapOf[K, V]) MarshalJSON() ([]byte, error) {
 	// Create a temporary map, which will hold all item spread across shards.
-	tmp := make(map[string]interface{})
+	tmp := make(map[K]V)
 
 	// Insert items to temporary map.
 	for item := range m.IterBuffered() {
@@ -292,24 +455,28 @@ func fnv32(key string) uint32 {
 	return hash
 }
 
-// Concurrent map uses Interface{} as its value,
-// therefor JSON Unmarshal probably won't know which to type to unmarshal into,
-// in such case we'll end up with a value of type map[string]interface{},
-// In most cases this isn't out value type, this is why we've decided to remove this functionality.
-
-// func (m *ConcurrentMap) UnmarshalJSON(b []byte) (err error) {
-// 	// Reverse process of Marshal.
-
-// 	tmp := make(map[string]interface{})
+// UnmarshalJSON Reverse process of MarshalJSON, inserts the decoded key,value pairs into the map.
+// Existing elements are kept unless overwritten, as json.Unmarshal does for a Go map.
+// A zero value map with string keys is initialized with ShardCount shards,
+// other zero value maps can not be unmarshalled since the sharding function is unknown.
+// Values of ConcurrentMap are decoded as json.Unmarshal does for interface{}, e.g. objects become map[string]interface{}.
+func (m *ConcurrentMapOf[K, V]) UnmarshalJSON(b []byte) error {
+	tmp := make(map[K]V)
+
+	// Unmarshal into a single map.
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
 
-// 	// Unmarshal into a single map.
-// 	if err := json.Unmarshal(b, &tmp); err != nil {
-// 		return nil
-// 	}
+	if m.shards == nil {
+		sharding, ok := interface{}(fnv32).(func(key K) uint32)
+		if !ok {
+			return errors.New("concurrent map: can not unmarshal into a zero value map without sharding function")
+		}
+		*m = newConcurrentMapOf[K, V](0, sharding)
+	}
 
-// 	// foreach key,value pair in temporary map insert into our concurrent map.
-// 	for key, val := range tmp {
-// 		m.Set(key, val)
-// 	}
-// 	return nil
-// }
+	// foreach key,value pair in temporary map insert into our concurrent map.
+	m.MSet(tmp)
+	return nil
+}
@@ -1,7 +1,10 @@
 package eureka_client
 
 import (
-	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
 )
 
 // Config eureka 客户端配置
@@ -12,22 +15,74 @@ type Config struct {
 	RenewalIntervalInSecs int
 	// 获取服务列表间隔，默认 15s
 	RegistryFetchIntervalSeconds int
+	// 与服务端交互的数据格式，ContentTypeJSON 或 ContentTypeXML，默认 ContentTypeJSON
+	ContentType string
+	// 拉取服务列表时请求 gzip 压缩并自动解压，大规模注册表可以显著减少流量与耗时
+	GzipRegistry bool
+	// 本地区域，默认 us-east-1
+	Region string
+	// 区域包含的可用区，用于根据实例的 availability-zone 判断实例所在的区域
+	AvailabilityZones map[string][]string
+	// 同时拉取服务列表的远程区域，服务端会将这些区域的实例合并到返回的服务列表中
+	FetchRemoteRegionsRegistry []string
+	// 该客户端调用 eureka REST 使用的 http.Client，可以自定义 Transport、超时、长连接与监控，为 nil 则使用 SetHTTPClient 设置的 http.Client
+	HTTPClient *http.Client
+	// 禁用增量获取服务列表，禁用后每次都全量获取
+	DisableDelta bool
 	// 过期间隔，默认 90s
 	DurationInSecs int
+	// BeatReactor 同时发送心跳的工作 goroutine 上限，默认 DefaultBeatThreadNum
+	BeatWorkers int
+	// 心跳连续失败时退避间隔的最大倍数，间隔每次失败翻倍直到心跳间隔的该倍数，默认 10
+	HeartbeatBackoffBound int
+	// 心跳连续失败（网络错误、5xx 等，不包括服务端返回 404）该次数后，先注销再重新注册实例，默认 0 不启用
+	ReRegisterAfterFailures int
+	// 心跳与获取服务列表间隔的随机抖动百分比（0-100），例如 10 表示在间隔的 ±10% 内随机，默认 0 不抖动
+	IntervalJitterPercent int
+	// 记录从注册表中消失的实例的保留时间，默认 300s，小于 0 则不记录
+	TombstoneWindowSecs int
+	// 注销前的排空时间，先将实例状态覆盖为 OUT_OF_SERVICE，等待调用方与其缓存不再使用该实例后再注销，默认 0 直接注销
+	DrainDurationInSecs int
+	// 触发注销并退出进程的信号，默认 SIGTERM 与 SIGINT
+	ShutdownSignals []os.Signal
 	// 实例ID，默认 app:ip:port
 	InstanceID string
 	// 应用名称
 	App string
 	// 应用版本
 	Version string
-	// Host，为空则取 IP
+	// Host，为空时 PreferIPAddress 为 true 则取 IP，否则反向解析 IP 得到完整域名，解析失败时取操作系统的主机名
 	HostName string
+	// 注册与构建实例 url（HomePageURL 等）时使用 IP 而不是 HostName，调用方无法解析主机名时开启
+	PreferIPAddress bool
 	// IP，为空则取本地 IP
 	IP string
+	// 获取本地 IP 时使用的网卡名称，例如 eth1，用于多网卡主机选择注册的地址，为空则取第一个非回环的 IPv4 地址
+	// 该网卡不存在或没有 IPv4 地址时仍取第一个非回环的 IPv4 地址
+	NetworkInterface string
+	// 获取本地 IP 时优先选择的网段，CIDR 格式，例如 10.2.0.0/16，按顺序匹配，都不匹配时取第一个非回环的 IPv4 地址
+	// 与 Spring Cloud 的 spring.cloud.inetutils.preferred-networks 类似
+	PreferredNetworks []string
 	// 端口，默认 80
 	Port int
-	// 元数据
+	// 安全端口（https），默认 443，SecurePortEnabled 为 true 时启用
+	SecurePort int
+	// 启用安全端口，实例的 HomePageURL 与 StatusPageURL 使用 https 与 SecurePort，适用于 TLS 服务
+	SecurePortEnabled bool
+	// 数据中心信息，例如 Netflix 内部或分支版本使用的 Class，为空则为 MyOwn，Name 为 MyOwn 或 Amazon 时可以不设置 Class
+	DataCenterInfo *DataCenterInfo
+	// 元数据，值会转换为字符串，无法转换的值（例如 map、slice）会导致注册失败，建议使用 InstanceMetadata
 	Metadata map[string]interface{}
+	// 字符串类型的元数据，与 Metadata 中相同的 key 以此为准
+	InstanceMetadata Metadata
+	// 时间源，默认 SystemClock
+	Clock Clock
+	// 应用名称转换，注册和查询服务实例时都会使用，为空则注册时转为小写、查询时精确匹配
+	NameMapper NameMapper
+	// 启动时必须存在 UP 实例的依赖应用，配合 Client.StartAndWaitReady 使用
+	RequiredApps []string
+	// 依赖应用在启动期限内没有 UP 实例时的处理策略，默认 RequiredAppsFail
+	RequiredAppsPolicy RequiredAppsPolicy
 }
 
 // Applications eureka 服务端注册的 apps
@@ -45,31 +100,34 @@ type Application struct {
 
 // Instance 服务实例
 type Instance struct {
-	HostName                      string                 `xml:"hostName" json:"hostName"`
-	HomePageURL                   string                 `xml:"homePageUrl,omitempty" json:"homePageUrl,omitempty"`
-	StatusPageURL                 string                 `xml:"statusPageUrl" json:"statusPageUrl"`
-	HealthCheckURL                string                 `xml:"healthCheckUrl,omitempty" json:"healthCheckUrl,omitempty"`
-	App                           string                 `xml:"app" json:"app"`
-	Version                       string                 `xml:"version" json:"version"`
-	IPAddr                        string                 `xml:"ipAddr" json:"ipAddr"`
-	VipAddress                    string                 `xml:"vipAddress" json:"vipAddress"`
-	SecureVipAddress              string                 `xml:"secureVipAddress,omitempty" json:"secureVipAddress,omitempty"`
-	Status                        string                 `xml:"status" json:"status"`
-	Port                          *Port                  `xml:"port,omitempty" json:"port,omitempty"`
-	SecurePort                    *Port                  `xml:"securePort,omitempty" json:"securePort,omitempty"`
-	DataCenterInfo                *DataCenterInfo        `xml:"dataCenterInfo" json:"dataCenterInfo"`
-	LeaseInfo                     *LeaseInfo             `xml:"leaseInfo,omitempty" json:"leaseInfo,omitempty"`
-	Metadata                      map[string]interface{} `xml:"metadata,omitempty" json:"metadata,omitempty"`
-	IsCoordinatingDiscoveryServer string                 `xml:"isCoordinatingDiscoveryServer,omitempty" json:"isCoordinatingDiscoveryServer,omitempty"`
-	LastUpdatedTimestamp          string                 `xml:"lastUpdatedTimestamp,omitempty" json:"lastUpdatedTimestamp,omitempty"`
-	LastDirtyTimestamp            string                 `xml:"lastDirtyTimestamp,omitempty" json:"lastDirtyTimestamp,omitempty"`
-	ActionType                    string                 `xml:"actionType,omitempty" json:"actionType,omitempty"`
-	OverriddenStatus              string                 `xml:"overriddenstatus,omitempty" json:"overriddenstatus,omitempty"`
-	CountryID                     int                    `xml:"countryId,omitempty" json:"countryId,omitempty"`
-	InstanceID                    string                 `xml:"instanceId,omitempty" json:"instanceId,omitempty"`
+	HostName                      string          `xml:"hostName" json:"hostName"`
+	HomePageURL                   string          `xml:"homePageUrl,omitempty" json:"homePageUrl,omitempty"`
+	StatusPageURL                 string          `xml:"statusPageUrl" json:"statusPageUrl"`
+	HealthCheckURL                string          `xml:"healthCheckUrl,omitempty" json:"healthCheckUrl,omitempty"`
+	SecureHealthCheckURL          string          `xml:"secureHealthCheckUrl,omitempty" json:"secureHealthCheckUrl,omitempty"`
+	App                           string          `xml:"app" json:"app"`
+	AppGroupName                  string          `xml:"appGroupName,omitempty" json:"appGroupName,omitempty"`
+	ASGName                       string          `xml:"asgName,omitempty" json:"asgName,omitempty"`
+	Version                       string          `xml:"version" json:"version"`
+	IPAddr                        string          `xml:"ipAddr" json:"ipAddr"`
+	VipAddress                    string          `xml:"vipAddress" json:"vipAddress"`
+	SecureVipAddress              string          `xml:"secureVipAddress,omitempty" json:"secureVipAddress,omitempty"`
+	Status                        string          `xml:"status" json:"status"`
+	Port                          *Port           `xml:"port,omitempty" json:"port,omitempty"`
+	SecurePort                    *Port           `xml:"securePort,omitempty" json:"securePort,omitempty"`
+	DataCenterInfo                *DataCenterInfo `xml:"dataCenterInfo" json:"dataCenterInfo"`
+	LeaseInfo                     *LeaseInfo      `xml:"leaseInfo,omitempty" json:"leaseInfo,omitempty"`
+	Metadata                      Metadata        `xml:"metadata,omitempty" json:"metadata,omitempty"`
+	IsCoordinatingDiscoveryServer string          `xml:"isCoordinatingDiscoveryServer,omitempty" json:"isCoordinatingDiscoveryServer,omitempty"`
+	LastUpdatedTimestamp          string          `xml:"lastUpdatedTimestamp,omitempty" json:"lastUpdatedTimestamp,omitempty"`
+	LastDirtyTimestamp            string          `xml:"lastDirtyTimestamp,omitempty" json:"lastDirtyTimestamp,omitempty"`
+	ActionType                    string          `xml:"actionType,omitempty" json:"actionType,omitempty"`
+	OverriddenStatus              string          `xml:"overriddenstatus,omitempty" json:"overriddenstatus,omitempty"`
+	CountryID                     int             `xml:"countryId,omitempty" json:"countryId,omitempty"`
+	InstanceID                    string          `xml:"instanceId,omitempty" json:"instanceId,omitempty"`
 
-	EurekaConfig *Config
-	Beater       *BeatReactor
+	// 所属 RegisteredInstance 的状态锁，见 stateLock
+	stateMutex *sync.RWMutex
 }
 
 // Port 端口
@@ -104,6 +162,11 @@ type DataCenterMetadata struct {
 type LeaseInfo struct {
 	RenewalIntervalInSecs int `xml:"renewalIntervalInSecs,omitempty" json:"renewalIntervalInSecs,omitempty"`
 	DurationInSecs        int `xml:"durationInSecs,omitempty" json:"durationInSecs,omitempty"`
+	// 以下时间戳由服务端维护，注册时不需要填写
+	RegistrationTimestamp int64 `xml:"registrationTimestamp,omitempty" json:"registrationTimestamp,omitempty"`
+	LastRenewalTimestamp  int64 `xml:"lastRenewalTimestamp,omitempty" json:"lastRenewalTimestamp,omitempty"`
+	EvictionTimestamp     int64 `xml:"evictionTimestamp,omitempty" json:"evictionTimestamp,omitempty"`
+	ServiceUpTimestamp    int64 `xml:"serviceUpTimestamp,omitempty" json:"serviceUpTimestamp,omitempty"`
 }
 
 // NewInstance 创建服务实例
@@ -118,6 +181,10 @@ func NewInstance(config *Config) *Instance {
 			Port:    config.Port,
 			Enabled: "true",
 		},
+		SecurePort: &Port{
+			Port:    config.SecurePort,
+			Enabled: strconv.FormatBool(config.SecurePortEnabled),
+		},
 		VipAddress:       config.App,
 		SecureVipAddress: config.App,
 		// 续约信息
@@ -128,17 +195,12 @@ func NewInstance(config *Config) *Instance {
 		Status:           "UP",
 		OverriddenStatus: "UNKNOWN",
 		// 数据中心
-		DataCenterInfo: &DataCenterInfo{
-			Name:  "MyOwn",
-			Class: "com.netflix.appinfo.InstanceInfo$DefaultDataCenterInfo",
-		},
+		DataCenterInfo: config.dataCenterInfo(),
 		// 元数据
-		Metadata: config.Metadata,
+		Metadata: config.instanceMetadata(),
 	}
-	instance.HomePageURL = fmt.Sprintf("%s://%s:%d", "http", config.IP, config.Port)
-	instance.StatusPageURL = fmt.Sprintf("%s://%s:%d/info", "http", config.IP, config.Port)
-	instance.EurekaConfig = config
-	beater := NewBeatReactor(config, int64(config.RenewalIntervalInSecs))
-	instance.Beater = &beater
+	instance.HomePageURL = config.baseURL()
+	instance.StatusPageURL = config.baseURL() + "/info"
+	instance.setDirty(config.clock().Now())
 	return instance
 }
@@ -0,0 +1,51 @@
+package fasthttpadapter
+
+import (
+	"net/url"
+
+	"github.com/valyala/fasthttp"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+// Rewrite 将 eureka://APP/path 请求的 scheme 与 host 改写为某个 UP 状态实例的地址，其他请求保持不变
+func Rewrite(resolver *eureka.Resolver, req *fasthttp.Request) error {
+	uri := req.URI()
+	if string(uri.Scheme()) != eureka.Scheme {
+		return nil
+	}
+	target, err := resolver.ResolveServiceURL(string(uri.Host()))
+	if err != nil {
+		return err
+	}
+	base, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+	uri.SetScheme(base.Scheme)
+	uri.SetHost(base.Host)
+	req.Header.SetHost(base.Host)
+	return nil
+}
+
+// Client 包装 fasthttp.Client，发送前改写 eureka://APP/path 请求
+type Client struct {
+	*fasthttp.Client
+	Resolver *eureka.Resolver
+}
+
+// NewClient 创建 Client，client 为 nil 则使用默认的 fasthttp.Client
+func NewClient(resolver *eureka.Resolver, client *fasthttp.Client) *Client {
+	if client == nil {
+		client = &fasthttp.Client{}
+	}
+	return &Client{Client: client, Resolver: resolver}
+}
+
+// Do 改写请求后发送
+func (c *Client) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	if err := Rewrite(c.Resolver, req); err != nil {
+		return err
+	}
+	return c.Client.Do(req, resp)
+}
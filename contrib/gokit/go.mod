@@ -0,0 +1,13 @@
+module github.com/godoes/eureka-client/contrib/gokit
+
+go 1.18
+
+require (
+	github.com/go-kit/kit v0.13.0
+	github.com/go-kit/log v0.2.0
+	github.com/godoes/eureka-client v0.0.0
+)
+
+require github.com/go-logfmt/logfmt v0.5.1 // indirect
+
+replace github.com/godoes/eureka-client => ../../
@@ -0,0 +1,99 @@
+package gokit
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/go-kit/kit/sd"
+	"github.com/go-kit/log"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+// Instancer 基于 eureka 注册表实现 go-kit 的 sd.Instancer
+// 通过 Client.Watch 订阅应用的实例变化，应用名称的处理与 Resolver 相同，并将 UP 状态的实例以 host:port 形式推送给订阅者
+type Instancer struct {
+	app    string
+	logger log.Logger
+
+	mutex     sync.RWMutex
+	state     sd.Event
+	receivers map[chan<- sd.Event]struct{}
+
+	// 取消 Watch 的订阅
+	stopWatch func()
+	once      sync.Once
+}
+
+var _ sd.Instancer = (*Instancer)(nil)
+
+// NewInstancer 创建 Instancer，app 为需要订阅的应用名称
+func NewInstancer(client *eureka.Client, app string, logger log.Logger) *Instancer {
+	s := &Instancer{
+		app:       app,
+		logger:    log.With(logger, "app", app),
+		receivers: make(map[chan<- sd.Event]struct{}),
+	}
+	changes, stop := client.Watch(app)
+	s.stopWatch = stop
+	// Watch 返回的 channel 先收到当前的实例
+	s.update(endpoints(<-changes))
+	go s.loop(changes)
+	return s
+}
+
+// loop 服务列表刷新后实例有变化时通知订阅者，取消订阅或客户端停止后 channel 关闭时退出
+func (s *Instancer) loop(changes <-chan []eureka.Instance) {
+	for instances := range changes {
+		s.update(endpoints(instances))
+	}
+}
+
+// endpoints 将实例转换为排序后的 host:port 地址
+func endpoints(instances []eureka.Instance) []string {
+	addresses := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		if instance.Port == nil {
+			continue
+		}
+		addresses = append(addresses, instance.IPAddr+":"+strconv.Itoa(instance.Port.Int()))
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// update 实例发生变化时通知所有订阅者
+func (s *Instancer) update(instances []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if reflect.DeepEqual(s.state.Instances, instances) {
+		return
+	}
+	_ = s.logger.Log("instances", len(instances))
+	s.state = sd.Event{Instances: instances}
+	for c := range s.receivers {
+		c <- s.state
+	}
+}
+
+// Register 订阅实例变化，订阅时会立即收到当前的实例列表
+func (s *Instancer) Register(ch chan<- sd.Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.receivers[ch] = struct{}{}
+	ch <- s.state
+}
+
+// Deregister 取消订阅
+func (s *Instancer) Deregister(ch chan<- sd.Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.receivers, ch)
+}
+
+// Stop 取消订阅实例变化
+func (s *Instancer) Stop() {
+	s.once.Do(s.stopWatch)
+}
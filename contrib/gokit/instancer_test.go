@@ -0,0 +1,47 @@
+package gokit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/sd"
+	"github.com/go-kit/log"
+
+	eureka "github.com/godoes/eureka-client"
+	"github.com/godoes/eureka-client/eurekatest"
+)
+
+func TestInstancer(t *testing.T) {
+	srv := eurekatest.NewServer()
+	defer srv.Close()
+	srv.Register(eureka.Instance{App: "ORDER", InstanceID: "order-1", IPAddr: "10.0.0.1", Status: eureka.StatusUp,
+		Port: &eureka.Port{Port: 8080, Enabled: "true"}})
+
+	client := eureka.NewClient(&eureka.Config{DefaultZone: srv.Zone(), App: "me", Port: 1})
+	if _, err := client.RefreshNow(); err != nil {
+		t.Fatal(err)
+	}
+	// 应用名称为小写时与 Resolver 一样转换为大写
+	s := NewInstancer(client, "order", log.NewNopLogger())
+	defer s.Stop()
+
+	events := make(chan sd.Event, 1)
+	s.Register(events)
+	if event := <-events; len(event.Instances) != 1 || event.Instances[0] != "10.0.0.1:8080" {
+		t.Fatalf("instances = %v, want [10.0.0.1:8080]", event.Instances)
+	}
+
+	srv.Register(eureka.Instance{App: "ORDER", InstanceID: "order-2", IPAddr: "10.0.0.2", Status: eureka.StatusUp,
+		Port: &eureka.Port{Port: 8080, Enabled: "true"}})
+	if _, err := client.RefreshNow(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-events:
+		if len(event.Instances) != 2 {
+			t.Fatalf("instances = %v, want 2 instances", event.Instances)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event after the registry changed")
+	}
+}
@@ -0,0 +1,45 @@
+package gokit
+
+import (
+	"github.com/go-kit/kit/sd"
+	"github.com/go-kit/log"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+// Registrar 基于 eureka 客户端实现 go-kit 的 sd.Registrar
+// 注册成功后由客户端的 BeatReactor 负责续约心跳
+type Registrar struct {
+	client *eureka.Client
+	logger log.Logger
+}
+
+var _ sd.Registrar = (*Registrar)(nil)
+
+// NewRegistrar 创建 Registrar
+func NewRegistrar(client *eureka.Client, logger log.Logger) *Registrar {
+	return &Registrar{
+		client: client,
+		logger: log.With(logger, "app", client.Config.App, "instance", client.Instance.InstanceID),
+	}
+}
+
+// Register 注册实例，成功后开始发送心跳
+func (r *Registrar) Register() {
+	err := r.client.Instance.Register()
+	if err != nil {
+		_ = r.logger.Log("action", "register", "err", err)
+		return
+	}
+	_ = r.logger.Log("action", "register")
+}
+
+// Deregister 删除实例，成功后停止发送心跳
+func (r *Registrar) Deregister() {
+	err := r.client.Instance.UnRegister()
+	if err != nil {
+		_ = r.logger.Log("action", "deregister", "err", err)
+		return
+	}
+	_ = r.logger.Log("action", "deregister")
+}
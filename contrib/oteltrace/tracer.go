@@ -0,0 +1,51 @@
+package oteltrace
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+const instrumentationName = "github.com/godoes/eureka-client/contrib/oteltrace"
+
+// NewTracer 基于 TracerProvider 创建 eureka.APITracer，provider 为 nil 则使用全局的 TracerProvider
+// 每次 eureka REST 调用都会生成一个 eureka.<operation> 的 client span，请求使用带有该 span 的上下文发送，
+// http 客户端使用 otelhttp 等传输层时以该 span 为父级并向服务端传播
+func NewTracer(provider trace.TracerProvider) eureka.APITracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	tracer := provider.Tracer(instrumentationName)
+	return func(ctx context.Context, op, zone string) (context.Context, func(statusCode int, err error)) {
+		ctx, span := tracer.Start(ctx, "eureka."+op,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("eureka.operation", op),
+				attribute.String("eureka.zone", zone),
+			),
+		)
+		return ctx, func(statusCode int, err error) {
+			if statusCode != 0 {
+				span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+			}
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			} else if statusCode >= http.StatusBadRequest {
+				span.SetStatus(codes.Error, http.StatusText(statusCode))
+			}
+			span.End()
+		}
+	}
+}
+
+// Install 使用 provider 跟踪所有 eureka REST 调用
+func Install(provider trace.TracerProvider) {
+	eureka.SetAPITracer(NewTracer(provider))
+}
@@ -0,0 +1,197 @@
+package prommetrics
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+const namespace = "eureka_client"
+
+// Collector 实现 prometheus.Collector 与 eureka.Metrics，导出客户端的心跳、拉取服务列表等运行指标
+type Collector struct {
+	client *eureka.Client
+
+	heartbeats    *prometheus.CounterVec
+	fetches       *prometheus.CounterVec
+	fetchDuration prometheus.Histogram
+	reRegisters   *prometheus.CounterVec
+
+	instanceStatus *prometheus.Desc
+	registryApps   *prometheus.Desc
+	registrySize   *prometheus.Desc
+	lastHeartbeat  *prometheus.Desc
+	degraded       *prometheus.Desc
+
+	mutex             sync.RWMutex
+	apps              int
+	instances         int
+	lastHeartbeatTime time.Time
+}
+
+var (
+	_ prometheus.Collector     = (*Collector)(nil)
+	_ eureka.Metrics           = (*Collector)(nil)
+	_ eureka.ReRegisterMetrics = (*Collector)(nil)
+)
+
+// NewCollector 创建 Collector，并设置为客户端的运行指标观察者
+func NewCollector(client *eureka.Client) *Collector {
+	labels := prometheus.Labels{"app": client.Config.App, "instance_id": client.Instance.InstanceID}
+	c := &Collector{
+		client: client,
+		heartbeats: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "heartbeats_total",
+			Help:        "Total number of heartbeats sent to the eureka server, partitioned by result (success, failure, conflict).",
+			ConstLabels: labels,
+		}, []string{"result"}),
+		fetches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "registry_fetches_total",
+			Help:        "Total number of registry fetches from the eureka server, partitioned by result.",
+			ConstLabels: labels,
+		}, []string{"result"}),
+		fetchDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Name:        "registry_fetch_duration_seconds",
+			Help:        "Duration of registry fetches from the eureka server.",
+			ConstLabels: labels,
+			Buckets:     prometheus.DefBuckets,
+		}),
+		reRegisters: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "reregistrations_total",
+			Help:        "Total number of re-registrations after consecutive heartbeat failures, partitioned by result.",
+			ConstLabels: labels,
+		}, []string{"result"}),
+		instanceStatus: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "instance_status"),
+			"Status of the registered instance, 1 for the current status.",
+			[]string{"status"}, labels,
+		),
+		registryApps: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "registry_applications"),
+			"Number of applications in the cached registry.",
+			nil, labels,
+		),
+		registrySize: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "registry_instances"),
+			"Number of instances in the cached registry.",
+			nil, labels,
+		),
+		lastHeartbeat: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "last_successful_heartbeat_timestamp_seconds"),
+			"Unix timestamp of the last successful heartbeat.",
+			nil, labels,
+		),
+		degraded: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "degraded"),
+			"Whether heartbeats are failing and backing off, 1 for degraded.",
+			nil, labels,
+		),
+	}
+	for _, result := range []string{"success", "failure"} {
+		c.heartbeats.WithLabelValues(result)
+		c.fetches.WithLabelValues(result)
+		c.reRegisters.WithLabelValues(result)
+	}
+	// 心跳时服务端返回 409 冲突，客户端会重新注册
+	c.heartbeats.WithLabelValues("conflict")
+	client.SetMetrics(c)
+	return c
+}
+
+// Register 创建 Collector 并注册到 registerer
+func Register(client *eureka.Client, registerer prometheus.Registerer) (*Collector, error) {
+	c := NewCollector(client)
+	if err := registerer.Register(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// HeartbeatCompleted 实现 eureka.Metrics
+func (c *Collector) HeartbeatCompleted(err error) {
+	if errors.Is(err, eureka.ErrConflict) {
+		c.heartbeats.WithLabelValues("conflict").Inc()
+		return
+	}
+	if err != nil {
+		c.heartbeats.WithLabelValues("failure").Inc()
+		return
+	}
+	c.heartbeats.WithLabelValues("success").Inc()
+	c.mutex.Lock()
+	c.lastHeartbeatTime = time.Now()
+	c.mutex.Unlock()
+}
+
+// RegistryFetched 实现 eureka.Metrics
+func (c *Collector) RegistryFetched(apps *eureka.Applications, duration time.Duration, err error) {
+	c.fetchDuration.Observe(duration.Seconds())
+	if err != nil {
+		c.fetches.WithLabelValues("failure").Inc()
+		return
+	}
+	c.fetches.WithLabelValues("success").Inc()
+	instances := 0
+	for _, app := range apps.Applications {
+		instances += len(app.Instances)
+	}
+	c.mutex.Lock()
+	c.apps = len(apps.Applications)
+	c.instances = instances
+	c.mutex.Unlock()
+}
+
+// ReRegistered 实现 eureka.ReRegisterMetrics
+func (c *Collector) ReRegistered(_ int, err error) {
+	if err != nil {
+		c.reRegisters.WithLabelValues("failure").Inc()
+		return
+	}
+	c.reRegisters.WithLabelValues("success").Inc()
+}
+
+// Describe 实现 prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.heartbeats.Describe(ch)
+	c.fetches.Describe(ch)
+	c.fetchDuration.Describe(ch)
+	c.reRegisters.Describe(ch)
+	ch <- c.instanceStatus
+	ch <- c.registryApps
+	ch <- c.registrySize
+	ch <- c.lastHeartbeat
+	ch <- c.degraded
+}
+
+// Collect 实现 prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.heartbeats.Collect(ch)
+	c.fetches.Collect(ch)
+	c.fetchDuration.Collect(ch)
+	c.reRegisters.Collect(ch)
+
+	ch <- prometheus.MustNewConstMetric(c.instanceStatus, prometheus.GaugeValue, 1, c.client.InstanceStatus())
+	var degraded float64
+	if c.client.Stats().Degraded {
+		degraded = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.degraded, prometheus.GaugeValue, degraded)
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	ch <- prometheus.MustNewConstMetric(c.registryApps, prometheus.GaugeValue, float64(c.apps))
+	ch <- prometheus.MustNewConstMetric(c.registrySize, prometheus.GaugeValue, float64(c.instances))
+	var last float64
+	if !c.lastHeartbeatTime.IsZero() {
+		last = float64(c.lastHeartbeatTime.UnixNano()) / 1e9
+	}
+	ch <- prometheus.MustNewConstMetric(c.lastHeartbeat, prometheus.GaugeValue, last)
+}
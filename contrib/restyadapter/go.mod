@@ -0,0 +1,12 @@
+module github.com/godoes/eureka-client/contrib/restyadapter
+
+go 1.20
+
+require (
+	github.com/go-resty/resty/v2 v2.16.5
+	github.com/godoes/eureka-client v0.0.0
+)
+
+require golang.org/x/net v0.33.0 // indirect
+
+replace github.com/godoes/eureka-client => ../../
@@ -0,0 +1,38 @@
+package restyadapter
+
+import (
+	"net/url"
+
+	"github.com/go-resty/resty/v2"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+// Middleware 返回 resty 的请求中间件，将 eureka://APP/path 请求改写为某个 UP 状态实例的地址
+//
+//	client := resty.New().OnBeforeRequest(restyadapter.Middleware(resolver))
+//	resp, err := client.R().Get("eureka://order-service/api/orders")
+//
+// 注意改写后 resty 的重试会复用同一个实例，需要跨实例重试时请使用 Transport：
+//
+//	client := resty.New().SetTransport(eureka.NewTransport(eurekaClient, nil))
+func Middleware(resolver *eureka.Resolver) resty.RequestMiddleware {
+	return func(_ *resty.Client, req *resty.Request) error {
+		u, err := url.Parse(req.URL)
+		if err != nil || u.Scheme != eureka.Scheme {
+			return nil
+		}
+		target, err := resolver.ResolveServiceURL(u.Hostname())
+		if err != nil {
+			return err
+		}
+		base, err := url.Parse(target)
+		if err != nil {
+			return err
+		}
+		u.Scheme = base.Scheme
+		u.Host = base.Host
+		req.URL = u.String()
+		return nil
+	}
+}
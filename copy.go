@@ -0,0 +1,61 @@
+package eureka_client
+
+// deepCopy 深拷贝服务列表，修改副本不会影响原服务列表
+func (apps *Applications) deepCopy() *Applications {
+	if apps == nil {
+		return nil
+	}
+	cp := *apps
+	cp.Applications = make([]Application, len(apps.Applications))
+	for i, app := range apps.Applications {
+		cp.Applications[i] = Application{
+			Name:      app.Name,
+			Instances: make([]Instance, len(app.Instances)),
+		}
+		for j := range app.Instances {
+			cp.Applications[i].Instances[j] = app.Instances[j].deepCopy()
+		}
+	}
+	return &cp
+}
+
+// deepCopy 深拷贝服务实例，包括端口、数据中心、租约信息与元数据
+func (i *Instance) deepCopy() Instance {
+	cp := *i
+	// 副本与原实例的状态互不影响，不共享状态锁
+	cp.stateMutex = nil
+	if i.Port != nil {
+		port := *i.Port
+		cp.Port = &port
+	}
+	if i.SecurePort != nil {
+		port := *i.SecurePort
+		cp.SecurePort = &port
+	}
+	if i.DataCenterInfo != nil {
+		info := *i.DataCenterInfo
+		if info.Metadata != nil {
+			metadata := *info.Metadata
+			info.Metadata = &metadata
+		}
+		cp.DataCenterInfo = &info
+	}
+	if i.LeaseInfo != nil {
+		lease := *i.LeaseInfo
+		cp.LeaseInfo = &lease
+	}
+	if i.Metadata != nil {
+		cp.Metadata = make(Metadata, len(i.Metadata))
+		for k, v := range i.Metadata {
+			cp.Metadata[k] = v
+		}
+	}
+	return cp
+}
+
+// GetApplications 获取缓存的服务列表的深拷贝，还没有拉取到服务列表时返回 nil
+func (c *Client) GetApplications() *Applications {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.Applications.deepCopy()
+}
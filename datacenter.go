@@ -0,0 +1,42 @@
+package eureka_client
+
+// 数据中心名称与对应的 Class
+const (
+	DataCenterMyOwn        = "MyOwn"
+	DataCenterAmazon       = "Amazon"
+	DefaultDataCenterClass = "com.netflix.appinfo.InstanceInfo$DefaultDataCenterInfo"
+	AmazonDataCenterClass  = "com.netflix.appinfo.AmazonInfo"
+)
+
+// WithDataCenterInfo 使用自定义的数据中心信息注册实例，优先于 Config.DataCenterInfo
+func WithDataCenterInfo(info DataCenterInfo) Option {
+	return func(instance *Instance) {
+		instance.DataCenterInfo = newDataCenterInfo(&info)
+	}
+}
+
+// dataCenterInfo 注册实例使用的数据中心信息，未配置 Config.DataCenterInfo 时为 MyOwn
+func (c *Config) dataCenterInfo() *DataCenterInfo {
+	if c.DataCenterInfo == nil {
+		return &DataCenterInfo{Name: DataCenterMyOwn, Class: DefaultDataCenterClass}
+	}
+	return newDataCenterInfo(c.DataCenterInfo)
+}
+
+// newDataCenterInfo 复制数据中心信息，未设置 Class 时按名称补全 MyOwn 与 Amazon 的 Class
+func newDataCenterInfo(info *DataCenterInfo) *DataCenterInfo {
+	cp := *info
+	if info.Metadata != nil {
+		metadata := *info.Metadata
+		cp.Metadata = &metadata
+	}
+	if cp.Class == "" {
+		switch cp.Name {
+		case DataCenterMyOwn:
+			cp.Class = DefaultDataCenterClass
+		case DataCenterAmazon:
+			cp.Class = AmazonDataCenterClass
+		}
+	}
+	return &cp
+}
@@ -0,0 +1,106 @@
+package eureka_client
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// debugConfig 调试信息中的客户端配置，不包含函数等无法序列化的字段
+type debugConfig struct {
+	DefaultZone                  string   `json:"defaultZone"`
+	App                          string   `json:"app"`
+	InstanceID                   string   `json:"instanceId"`
+	HostName                     string   `json:"hostName"`
+	IP                           string   `json:"ip"`
+	Port                         int      `json:"port"`
+	RenewalIntervalInSecs        int      `json:"renewalIntervalInSecs"`
+	RegistryFetchIntervalSeconds int      `json:"registryFetchIntervalSeconds"`
+	DurationInSecs               int      `json:"durationInSecs"`
+	ContentType                  string   `json:"contentType"`
+	DisableDelta                 bool     `json:"disableDelta"`
+	GzipRegistry                 bool     `json:"gzipRegistry"`
+	Region                       string   `json:"region"`
+	FetchRemoteRegionsRegistry   []string `json:"fetchRemoteRegionsRegistry,omitempty"`
+	RequiredApps                 []string `json:"requiredApps,omitempty"`
+}
+
+// debugInfo DebugHandler 输出的客户端状态
+type debugInfo struct {
+	Config           debugConfig      `json:"config"`
+	State            string           `json:"state"`
+	Instance         *Instance        `json:"instance"`
+	Beating          bool             `json:"beating"`
+	Instances        []*Instance      `json:"additionalInstances,omitempty"`
+	Stats            ClientStats      `json:"stats"`
+	HeartbeatHistory []HeartbeatEvent `json:"heartbeatHistory"`
+	Beats            BeatReport       `json:"beats"`
+	Goroutines       GoroutineReport  `json:"goroutines"`
+	Generation       uint64           `json:"generation"`
+	Applications     *Applications    `json:"applications"`
+}
+
+// DebugHandler 以 JSON 输出客户端当前的注册状态、配置、心跳记录与缓存的服务列表，可以挂载到 /debug/eureka
+func (c *Client) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(c.debugInfo())
+	})
+}
+
+// debugInfo 收集客户端当前的状态
+func (c *Client) debugInfo() debugInfo {
+	config := c.Config
+	renewal, fetch := c.intervals()
+	info := debugInfo{
+		Config: debugConfig{
+			DefaultZone:                  RedactURL(config.DefaultZone),
+			App:                          config.App,
+			InstanceID:                   config.InstanceID,
+			HostName:                     config.HostName,
+			IP:                           config.IP,
+			Port:                         config.Port,
+			RenewalIntervalInSecs:        int(renewal / time.Second),
+			RegistryFetchIntervalSeconds: int(fetch / time.Second),
+			DurationInSecs:               config.DurationInSecs,
+			ContentType:                  config.ContentType,
+			DisableDelta:                 config.DisableDelta,
+			GzipRegistry:                 config.GzipRegistry,
+			Region:                       config.Region,
+			FetchRemoteRegionsRegistry:   config.FetchRemoteRegionsRegistry,
+			RequiredApps:                 config.RequiredApps,
+		},
+		State:            c.State().String(),
+		Stats:            c.Stats(),
+		HeartbeatHistory: c.HeartbeatHistory(),
+		Goroutines:       c.GoroutineReport(),
+	}
+	if c.Instance != nil {
+		instance := c.Instance.snapshot()
+		info.Instance = &instance
+		// 客户端自身的实例由心跳循环发送心跳，已注册（包括心跳连续失败）时心跳循环在运行
+		state := c.State()
+		info.Beating = state == StateRegistered || state == StateDegraded
+	}
+	for _, beater := range c.beaters() {
+		report := beater.Report()
+		info.Beats.Instances = append(info.Beats.Instances, report.Instances...)
+		info.Beats.Overdue += report.Overdue
+	}
+	sort.Slice(info.Beats.Instances, func(i, j int) bool {
+		return info.Beats.Instances[i].InstanceID < info.Beats.Instances[j].InstanceID
+	})
+	for _, ri := range c.Instances() {
+		instance := ri.snapshot()
+		info.Instances = append(info.Instances, &instance)
+	}
+
+	c.mutex.RLock()
+	info.Generation = c.generation
+	info.Applications = c.Applications.deepCopy()
+	c.mutex.RUnlock()
+	return info
+}
@@ -0,0 +1,195 @@
+package eureka_client
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 增量变更类型
+const (
+	ActionAdded    = "ADDED"
+	ActionModified = "MODIFIED"
+	ActionDeleted  = "DELETED"
+)
+
+// maxChangeLogEntries 变更日志保留的最大条数，超出的部分合并到全量快照中
+const maxChangeLogEntries = 1000
+
+// ChangeLogEntry 已合并到本地缓存的一条增量变更
+type ChangeLogEntry struct {
+	// 变更序号，从 1 开始递增
+	Seq uint64
+	// 变更类型：ADDED、MODIFIED、DELETED
+	ActionType string
+	// 应用名称
+	App string
+	// 变更的实例
+	Instance Instance
+	// 合并到本地缓存的时间
+	Time time.Time
+}
+
+// changeLog 增量变更日志，记录最近一次全量快照之后合并的所有增量变更
+type changeLog struct {
+	mutex   sync.RWMutex
+	base    *Applications
+	entries []ChangeLogEntry
+	seq     uint64
+}
+
+// reset 全量拉取后以新的快照为基础，清空变更日志
+func (l *changeLog) reset(apps *Applications) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.base = copyApplications(apps)
+	l.entries = nil
+}
+
+// append 追加增量变更，超出最大条数时将最早的变更合并到全量快照中
+func (l *changeLog) append(delta *Applications, now time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, app := range delta.Applications {
+		for _, instance := range app.Instances {
+			l.seq++
+			l.entries = append(l.entries, ChangeLogEntry{
+				Seq:        l.seq,
+				ActionType: instance.ActionType,
+				App:        app.Name,
+				Instance:   instance,
+				Time:       now,
+			})
+		}
+	}
+	if n := len(l.entries) - maxChangeLogEntries; n > 0 {
+		if l.base != nil {
+			applyChanges(l.base, l.entries[:n])
+		}
+		l.entries = append([]ChangeLogEntry(nil), l.entries[n:]...)
+	}
+}
+
+// rebuild 在最近一次全量快照上重放变更日志，重建服务列表
+func (l *changeLog) rebuild() (*Applications, bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	if l.base == nil {
+		return nil, false
+	}
+	apps := copyApplications(l.base)
+	applyChanges(apps, l.entries)
+	return apps, true
+}
+
+// since 获取序号大于 seq 的变更，seq 早于保留的变更日志时返回 false，需要重新获取全量服务列表
+func (l *changeLog) since(seq uint64) ([]ChangeLogEntry, bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	if len(l.entries) > 0 && seq+1 < l.entries[0].Seq {
+		return nil, false
+	}
+	if len(l.entries) == 0 && seq < l.seq {
+		return nil, false
+	}
+	entries := make([]ChangeLogEntry, 0)
+	for _, entry := range l.entries {
+		if entry.Seq > seq {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, true
+}
+
+// ChangesSince 获取序号大于 seq 的增量变更，用于监听者重放变更
+// 返回 false 表示 seq 之后的部分变更已不在变更日志中（或者发生过全量拉取），需要通过 GetApplicationInstance 等方法重新获取
+func (c *Client) ChangesSince(seq uint64) ([]ChangeLogEntry, bool) {
+	return c.changeLog.since(seq)
+}
+
+// applyChanges 将变更合并到服务列表中
+func applyChanges(apps *Applications, entries []ChangeLogEntry) {
+	for _, entry := range entries {
+		applyChange(apps, entry.App, entry.Instance)
+	}
+}
+
+// applyDelta 将增量拉取的结果合并到服务列表中
+func applyDelta(apps *Applications, delta *Applications) {
+	for _, app := range delta.Applications {
+		for _, instance := range app.Instances {
+			applyChange(apps, app.Name, instance)
+		}
+	}
+}
+
+// applyChange 按实例的 ActionType 合并一个实例的变更
+func applyChange(apps *Applications, name string, instance Instance) {
+	index := -1
+	for i := range apps.Applications {
+		if apps.Applications[i].Name == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		if instance.ActionType == ActionDeleted {
+			return
+		}
+		apps.Applications = append(apps.Applications, Application{Name: name})
+		index = len(apps.Applications) - 1
+	}
+
+	app := &apps.Applications[index]
+	instances := make([]Instance, 0, len(app.Instances)+1)
+	for _, exist := range app.Instances {
+		if exist.InstanceID != instance.InstanceID {
+			instances = append(instances, exist)
+		}
+	}
+	if instance.ActionType != ActionDeleted {
+		instances = append(instances, instance)
+	}
+	app.Instances = instances
+
+	if len(app.Instances) == 0 {
+		apps.Applications = append(apps.Applications[:index], apps.Applications[index+1:]...)
+	}
+}
+
+// copyApplications 复制服务列表，修改副本的应用与实例列表不会影响原服务列表
+func copyApplications(apps *Applications) *Applications {
+	if apps == nil {
+		return nil
+	}
+	cp := *apps
+	cp.Applications = make([]Application, len(apps.Applications))
+	for i, app := range apps.Applications {
+		cp.Applications[i] = Application{
+			Name:      app.Name,
+			Instances: append([]Instance(nil), app.Instances...),
+		}
+	}
+	return &cp
+}
+
+// reconcileHashcode 按 eureka 的规则计算服务列表的哈希，例如：DOWN_1_UP_5_
+func reconcileHashcode(apps *Applications) string {
+	counts := make(map[string]int)
+	for _, app := range apps.Applications {
+		for _, instance := range app.Instances {
+			counts[instance.Status]++
+		}
+	}
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	hashcode := ""
+	for _, status := range statuses {
+		hashcode += status + "_" + strconv.Itoa(counts[status]) + "_"
+	}
+	return hashcode
+}
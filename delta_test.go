@@ -0,0 +1,101 @@
+package eureka_client
+
+import (
+	"testing"
+	"time"
+)
+
+func deltaInstance(id, status, action string) Instance {
+	return Instance{InstanceID: id, Status: status, ActionType: action}
+}
+
+// instanceStatuses 返回应用下实例 ID 到状态的映射，应用不存在时返回 nil
+func instanceStatuses(apps *Applications, name string) map[string]string {
+	for _, app := range apps.Applications {
+		if app.Name == name {
+			statuses := make(map[string]string, len(app.Instances))
+			for _, instance := range app.Instances {
+				statuses[instance.InstanceID] = instance.Status
+			}
+			return statuses
+		}
+	}
+	return nil
+}
+
+func TestApplyChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		app      string
+		instance Instance
+		want     map[string]string
+	}{
+		{"add to existing app", "SVC", deltaInstance("c", StatusUp, ActionAdded),
+			map[string]string{"a": StatusUp, "b": StatusUp, "c": StatusUp}},
+		{"add to new app", "NEW", deltaInstance("n", StatusUp, ActionAdded),
+			map[string]string{"n": StatusUp}},
+		{"replace modified", "SVC", deltaInstance("a", StatusDown, ActionModified),
+			map[string]string{"a": StatusDown, "b": StatusUp}},
+		{"added existing replaces", "SVC", deltaInstance("b", StatusOutOfService, ActionAdded),
+			map[string]string{"a": StatusUp, "b": StatusOutOfService}},
+		{"delete", "SVC", deltaInstance("a", StatusUp, ActionDeleted),
+			map[string]string{"b": StatusUp}},
+		{"delete unknown app", "NONE", deltaInstance("x", StatusUp, ActionDeleted), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apps := &Applications{Applications: []Application{{Name: "SVC", Instances: []Instance{
+				deltaInstance("a", StatusUp, ""), deltaInstance("b", StatusUp, ""),
+			}}}}
+			applyChange(apps, tt.app, tt.instance)
+			got := instanceStatuses(apps, tt.app)
+			if len(got) != len(tt.want) {
+				t.Fatalf("instances = %v, want %v", got, tt.want)
+			}
+			for id, status := range tt.want {
+				if got[id] != status {
+					t.Fatalf("instances = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyChangeDeleteLastInstanceRemovesApp(t *testing.T) {
+	apps := &Applications{Applications: []Application{{Name: "SVC", Instances: []Instance{deltaInstance("a", StatusUp, "")}}}}
+	applyChange(apps, "SVC", deltaInstance("a", StatusUp, ActionDeleted))
+	if len(apps.Applications) != 0 {
+		t.Fatalf("applications = %+v, want empty", apps.Applications)
+	}
+}
+
+func TestChangeLogRebuild(t *testing.T) {
+	base := &Applications{Applications: []Application{{Name: "SVC", Instances: []Instance{
+		deltaInstance("a", StatusUp, ""), deltaInstance("b", StatusUp, ""),
+	}}}}
+	l := &changeLog{}
+	l.reset(base)
+	l.append(&Applications{Applications: []Application{{Name: "SVC", Instances: []Instance{
+		deltaInstance("a", StatusUp, ActionDeleted),
+		deltaInstance("c", StatusUp, ActionAdded),
+		deltaInstance("b", StatusDown, ActionModified),
+	}}}}, time.Now())
+
+	apps, ok := l.rebuild()
+	if !ok {
+		t.Fatal("rebuild failed after reset")
+	}
+	got := instanceStatuses(apps, "SVC")
+	if len(got) != 2 || got["b"] != StatusDown || got["c"] != StatusUp {
+		t.Fatalf("rebuilt instances = %v, want b DOWN and c UP", got)
+	}
+	// 重放不修改快照
+	if statuses := instanceStatuses(base, "SVC"); len(statuses) != 2 || statuses["a"] != StatusUp {
+		t.Fatalf("base modified: %v", statuses)
+	}
+
+	entries, ok := l.since(1)
+	if !ok || len(entries) != 2 || entries[0].Instance.InstanceID != "c" {
+		t.Fatalf("since(1) = %+v, %v, want entries c and b", entries, ok)
+	}
+}
@@ -0,0 +1,104 @@
+package eureka_client
+
+// DiscoveryClient 服务发现接口，由 *Client 实现，下游代码依赖该接口而不是 *Client，
+// 单元测试中可以替换为 eurekatest.Discovery 等不依赖注册中心的实现
+type DiscoveryClient interface {
+	// GetInstances 获取应用下所有 UP 状态的服务实例
+	GetInstances(app string) []Instance
+	// PickInstance 轮询选择应用下一个 UP 状态的服务实例，没有时返回 ErrNoInstance
+	PickInstance(app string) (*Instance, error)
+	// Watch 订阅应用下 UP 状态的服务实例，返回的 channel 先收到当前的实例，之后实例变化时收到变化后的全部实例，
+	// 只保留最新的实例列表，调用 stop 或客户端停止后关闭
+	Watch(app string) (instances <-chan []Instance, stop func())
+}
+
+var _ DiscoveryClient = (*Client)(nil)
+
+// watcher Watch 的订阅
+type watcher struct {
+	app string
+	ch  chan []Instance
+	// 最近一次发送的实例
+	last []Instance
+}
+
+// GetInstances 获取应用下所有 UP 状态的服务实例，应用名称的处理同 Resolver.Instances
+func (c *Client) GetInstances(app string) []Instance {
+	return c.resolver.Instances(app)
+}
+
+// PickInstance 轮询选择应用下一个 UP 状态的服务实例，没有时返回 ErrNoInstance
+func (c *Client) PickInstance(app string) (*Instance, error) {
+	return c.resolver.Pick(app)
+}
+
+// Watch 订阅应用下 UP 状态的服务实例，每次刷新服务列表后实例有变化时通知，客户端停止后关闭，见 DiscoveryClient.Watch
+func (c *Client) Watch(app string) (<-chan []Instance, func()) {
+	w := &watcher{app: app, ch: make(chan []Instance, 1)}
+	c.watchMutex.Lock()
+	if c.watchers == nil {
+		c.watchers = make(map[*watcher]struct{})
+	}
+	c.watchers[w] = struct{}{}
+	w.last = c.GetInstances(app)
+	w.ch <- w.last
+	if c.watchClosed {
+		// 客户端已停止，服务列表不会再变化
+		delete(c.watchers, w)
+		close(w.ch)
+	}
+	c.watchMutex.Unlock()
+
+	stop := func() {
+		c.watchMutex.Lock()
+		defer c.watchMutex.Unlock()
+		if _, ok := c.watchers[w]; ok {
+			delete(c.watchers, w)
+			close(w.ch)
+		}
+	}
+	return w.ch, stop
+}
+
+// closeWatchers 客户端停止时关闭所有订阅，之后调用返回的 stop 不会重复关闭
+func (c *Client) closeWatchers() {
+	c.watchMutex.Lock()
+	defer c.watchMutex.Unlock()
+	c.watchClosed = true
+	for w := range c.watchers {
+		delete(c.watchers, w)
+		close(w.ch)
+	}
+}
+
+// notifyWatchers 服务列表变化后，向实例有变化的订阅发送变化后的实例
+func (c *Client) notifyWatchers() {
+	c.watchMutex.Lock()
+	defer c.watchMutex.Unlock()
+	for w := range c.watchers {
+		instances := c.GetInstances(w.app)
+		if sameInstances(w.last, instances) {
+			continue
+		}
+		w.last = instances
+		offerLatest(w.ch, instances)
+	}
+}
+
+// sameInstances 两组实例是否相同，不考虑顺序，忽略续约信息等不影响调用的字段
+func sameInstances(a, b []Instance) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	byID := make(map[string]Instance, len(a))
+	for _, instance := range a {
+		byID[instance.InstanceID] = instance
+	}
+	for _, instance := range b {
+		before, ok := byID[instance.InstanceID]
+		if !ok || instanceChanged(before, instance) {
+			return false
+		}
+	}
+	return true
+}
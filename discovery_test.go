@@ -0,0 +1,43 @@
+package eureka_client_test
+
+import (
+	"testing"
+	"time"
+
+	eureka "github.com/godoes/eureka-client"
+	"github.com/godoes/eureka-client/eurekatest"
+)
+
+func TestWatchClosedOnStop(t *testing.T) {
+	srv := eurekatest.NewServer()
+	defer srv.Close()
+	c := eureka.NewClient(&eureka.Config{DefaultZone: srv.Zone(), App: "me", Port: 1})
+	c.Start()
+	if !srv.WaitRegistered("ME", c.Config.InstanceID, 3*time.Second) {
+		t.Fatal("instance not registered")
+	}
+
+	changes, stop := c.Watch("svc")
+	<-changes
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case _, ok := <-changes:
+		if ok {
+			t.Fatal("received instances after Stop, want channel closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("watch channel not closed after Stop")
+	}
+	// 停止后调用 stop 不会重复关闭
+	stop()
+
+	// 停止后订阅只收到当前的实例
+	changes, stop = c.Watch("svc")
+	defer stop()
+	<-changes
+	if _, ok := <-changes; ok {
+		t.Fatal("watch after Stop not closed")
+	}
+}
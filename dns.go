@@ -0,0 +1,113 @@
+package eureka_client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dnsFailureCooldown 连接失败的 IP 在该时间内排在其他 IP 之后
+const dnsFailureCooldown = 30 * time.Second
+
+// roundRobinDialer 当 eureka 服务端域名解析为多个 IP（DNS 轮询）时，在这些 IP 之间轮询建立连接，
+// 并记录每个 IP 最近一次连接失败的时间，冷却期内的 IP 排在最后尝试，避免固定连接到已经宕机的 IP
+type roundRobinDialer struct {
+	dialer   *net.Dialer
+	resolver *net.Resolver
+	cooldown time.Duration
+	counter  uint64
+
+	mutex    sync.Mutex
+	failures map[string]time.Time
+}
+
+func newRoundRobinDialer() *roundRobinDialer {
+	return &roundRobinDialer{
+		dialer: &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		},
+		resolver: net.DefaultResolver,
+		cooldown: dnsFailureCooldown,
+		failures: make(map[string]time.Time),
+	}
+}
+
+// DialContext 依次尝试解析出的 IP，直到连接成功
+func (d *roundRobinDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+	ips, err := d.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, ip := range d.order(ips) {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			d.markSuccess(ip)
+			return conn, nil
+		}
+		d.markFailure(ip)
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// order 轮询打乱 IP 顺序，冷却期内连接失败过的 IP 按失败时间排在最后
+func (d *roundRobinDialer) order(ips []string) []string {
+	n := atomic.AddUint64(&d.counter, 1)
+	ordered := make([]string, 0, len(ips))
+	for i := range ips {
+		ordered = append(ordered, ips[(n+uint64(i))%uint64(len(ips))])
+	}
+
+	now := time.Now()
+	d.mutex.Lock()
+	failedAt := make(map[string]time.Time, len(ordered))
+	for _, ip := range ordered {
+		if t, ok := d.failures[ip]; ok && now.Sub(t) < d.cooldown {
+			failedAt[ip] = t
+		}
+	}
+	d.mutex.Unlock()
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		ti, fi := failedAt[ordered[i]]
+		tj, fj := failedAt[ordered[j]]
+		if fi != fj {
+			return !fi
+		}
+		return ti.Before(tj)
+	})
+	return ordered
+}
+
+func (d *roundRobinDialer) markSuccess(ip string) {
+	d.mutex.Lock()
+	delete(d.failures, ip)
+	d.mutex.Unlock()
+}
+
+func (d *roundRobinDialer) markFailure(ip string) {
+	d.mutex.Lock()
+	d.failures[ip] = time.Now()
+	d.mutex.Unlock()
+}
+
+// newRoundRobinTransport 基于 http.DefaultTransport 的配置创建使用 roundRobinDialer 的 Transport
+func newRoundRobinTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = newRoundRobinDialer().DialContext
+	return transport
+}
@@ -0,0 +1,76 @@
+package eureka_client
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Deregister 注销客户端的实例与通过 AddInstance 注册的实例
+// 设置了 Config.DrainDurationInSecs 时先将这些实例的状态覆盖为 OUT_OF_SERVICE，
+// 等待排空时间让进行中的请求完成、调用方刷新缓存后再注销，避免滚动发布时直接注销导致调用方出现 5xx
+// 注销成功后客户端不再发送心跳与重新注册，再次调用直接返回 nil
+func (c *Client) Deregister() error {
+	return c.DeregisterContext(context.Background())
+}
+
+// DeregisterContext 与 Deregister 相同，ctx 取消或超时时停止排空并中止进行中的请求
+func (c *Client) DeregisterContext(ctx context.Context) error {
+	drain := time.Duration(c.Config.DrainDurationInSecs) * time.Second
+	return c.deregister(ctx, drain > 0, drain)
+}
+
+// deregister 注销所有实例，outOfService 为 true 时先将实例置为 OUT_OF_SERVICE 并等待排空 drain 时间
+func (c *Client) deregister(ctx context.Context, outOfService bool, drain time.Duration) error {
+	if c.isDeregistered() {
+		return nil
+	}
+	if outOfService {
+		c.drain(ctx, drain)
+	}
+	c.removeInstances(ctx)
+	err := c.doUnRegister(ctx)
+	if err != nil {
+		c.opLogger(OpUnRegister).Error("de-register application instance failed", err)
+		return err
+	}
+	c.mutex.Lock()
+	c.deregistered = true
+	c.mutex.Unlock()
+	c.opLogger(OpUnRegister).Info("de-register application instance successful")
+	c.updateState()
+	c.onDeregistered()
+	return nil
+}
+
+// isDeregistered 实例是否已通过 Deregister 注销
+func (c *Client) isDeregistered() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deregistered
+}
+
+// drain 将所有实例的状态覆盖为 OUT_OF_SERVICE，并等待排空时间，覆盖失败时仍然等待，ctx 结束时提前返回
+func (c *Client) drain(ctx context.Context, duration time.Duration) {
+	instances := append([]*RegisteredInstance{c.Instance}, c.Instances()...)
+	for _, instance := range instances {
+		status := instance.EffectiveStatus()
+		if err := instance.OutOfServiceContext(ctx); err != nil {
+			c.opLogger(OpStatusOverride).Error(fmt.Sprintf("set instance %s OUT_OF_SERVICE failed", instance.InstanceID), err)
+			continue
+		}
+		c.opLogger(OpStatusOverride).Infof("instance %s is OUT_OF_SERVICE", instance.InstanceID)
+		if instance == c.Instance {
+			c.onStatusChange(status, StatusOutOfService)
+		}
+	}
+	if duration > 0 {
+		c.logger.Infof("draining for %s before de-register", duration)
+		timer := time.NewTimer(duration)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package eurekatest
+
+import (
+	"testing"
+	"time"
+)
+
+// waitInterval WaitRegistered 与 WaitHeartbeats 轮询的检查间隔
+const waitInterval = 10 * time.Millisecond
+
+// WaitRegistered 等待实例注册，超过 timeout 仍未注册时返回 false，客户端在后台注册时使用
+func (s *Server) WaitRegistered(app, instanceID string, timeout time.Duration) bool {
+	return waitFor(timeout, func() bool {
+		_, ok := s.Instance(app, instanceID)
+		return ok
+	})
+}
+
+// WaitHeartbeats 等待实例收到至少 n 次心跳，超过 timeout 仍未收到时返回 false
+func (s *Server) WaitHeartbeats(app, instanceID string, n int, timeout time.Duration) bool {
+	return waitFor(timeout, func() bool {
+		return s.Heartbeats(app, instanceID) >= n
+	})
+}
+
+// AssertRegistered 断言实例已注册
+func (s *Server) AssertRegistered(t testing.TB, app, instanceID string) {
+	t.Helper()
+	if _, ok := s.Instance(app, instanceID); !ok {
+		t.Errorf("eurekatest: instance %s of app %s is not registered", instanceID, appName(app))
+	}
+}
+
+// AssertNotRegistered 断言实例未注册或已注销
+func (s *Server) AssertNotRegistered(t testing.TB, app, instanceID string) {
+	t.Helper()
+	if _, ok := s.Instance(app, instanceID); ok {
+		t.Errorf("eurekatest: instance %s of app %s is registered", instanceID, appName(app))
+	}
+}
+
+// AssertStatus 断言实例已注册且状态为 status
+func (s *Server) AssertStatus(t testing.TB, app, instanceID, status string) {
+	t.Helper()
+	instance, ok := s.Instance(app, instanceID)
+	if !ok {
+		t.Errorf("eurekatest: instance %s of app %s is not registered", instanceID, appName(app))
+		return
+	}
+	if instance.Status != status {
+		t.Errorf("eurekatest: instance %s of app %s status is %s, want %s", instanceID, appName(app), instance.Status, status)
+	}
+}
+
+// AssertInstanceCount 断言应用注册的实例数量
+func (s *Server) AssertInstanceCount(t testing.TB, app string, n int) {
+	t.Helper()
+	if got := len(s.Instances(app)); got != n {
+		t.Errorf("eurekatest: app %s has %d instances, want %d", appName(app), got, n)
+	}
+}
+
+// waitFor 轮询 cond 直到返回 true 或超过 timeout
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if cond() {
+			return true
+		}
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		time.Sleep(waitInterval)
+	}
+}
@@ -0,0 +1,118 @@
+package eurekatest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+// Discovery 实现 eureka.DiscoveryClient 的内存服务发现，不需要启动 Server，用于对依赖 DiscoveryClient 的代码做单元测试
+//
+//	discovery := eurekatest.NewDiscovery()
+//	discovery.SetInstances("order-service", eureka.Instance{InstanceID: "order-1", IPAddr: "127.0.0.1", Status: "UP"})
+//	svc := NewPaymentService(discovery)
+type Discovery struct {
+	mutex sync.Mutex
+	// 以大写的应用名称为 key
+	instances map[string][]eureka.Instance
+	counters  map[string]uint64
+	watchers  map[string]map[chan []eureka.Instance]struct{}
+}
+
+var _ eureka.DiscoveryClient = (*Discovery)(nil)
+
+// NewDiscovery 创建没有任何实例的内存服务发现
+func NewDiscovery() *Discovery {
+	return &Discovery{
+		instances: make(map[string][]eureka.Instance),
+		counters:  make(map[string]uint64),
+		watchers:  make(map[string]map[chan []eureka.Instance]struct{}),
+	}
+}
+
+// SetInstances 替换应用下的所有实例，不传实例则清空，并通知该应用的订阅
+func (d *Discovery) SetInstances(app string, instances ...eureka.Instance) {
+	app = strings.ToUpper(app)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.instances[app] = append([]eureka.Instance(nil), instances...)
+	up := d.upInstances(app)
+	for ch := range d.watchers[app] {
+		offer(ch, up)
+	}
+}
+
+// GetInstances 获取应用下所有 UP 状态的实例
+func (d *Discovery) GetInstances(app string) []eureka.Instance {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.upInstances(strings.ToUpper(app))
+}
+
+// PickInstance 轮询选择应用下一个 UP 状态的实例，没有时返回 eureka.ErrNoInstance
+func (d *Discovery) PickInstance(app string) (*eureka.Instance, error) {
+	app = strings.ToUpper(app)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	instances := d.upInstances(app)
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("%w: %s", eureka.ErrNoInstance, app)
+	}
+	d.counters[app]++
+	instance := instances[d.counters[app]%uint64(len(instances))]
+	return &instance, nil
+}
+
+// Watch 订阅应用下 UP 状态的实例，返回的 channel 先收到当前的实例，之后每次 SetInstances 时收到变化后的实例，
+// 只保留最新的实例列表，调用 stop 后关闭
+func (d *Discovery) Watch(app string) (<-chan []eureka.Instance, func()) {
+	app = strings.ToUpper(app)
+	ch := make(chan []eureka.Instance, 1)
+	d.mutex.Lock()
+	if d.watchers[app] == nil {
+		d.watchers[app] = make(map[chan []eureka.Instance]struct{})
+	}
+	d.watchers[app][ch] = struct{}{}
+	ch <- d.upInstances(app)
+	d.mutex.Unlock()
+
+	stop := func() {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		if _, ok := d.watchers[app][ch]; ok {
+			delete(d.watchers[app], ch)
+			close(ch)
+		}
+	}
+	return ch, stop
+}
+
+// upInstances 获取应用下 UP 状态的实例，调用时需持有 d.mutex
+func (d *Discovery) upInstances(app string) []eureka.Instance {
+	up := make([]eureka.Instance, 0, len(d.instances[app]))
+	for _, instance := range d.instances[app] {
+		if instance.Status == eureka.StatusUp {
+			up = append(up, instance)
+		}
+	}
+	return up
+}
+
+// offer 向容量为 1 的 channel 发送实例，channel 已满时丢弃未接收的旧值
+func offer(ch chan []eureka.Instance, instances []eureka.Instance) {
+	select {
+	case ch <- instances:
+		return
+	default:
+	}
+	select {
+	case <-ch:
+	default:
+	}
+	select {
+	case ch <- instances:
+	default:
+	}
+}
@@ -0,0 +1,404 @@
+// Package eurekatest 提供基于 httptest 的内存 eureka 服务端，用于在没有真实 eureka 的情况下对服务发现逻辑做集成测试
+//
+//	server := eurekatest.NewServer()
+//	defer server.Close()
+//	client := eureka.NewClient(&eureka.Config{DefaultZone: server.Zone(), App: "order-service"})
+//	client.Start()
+//	server.WaitRegistered("order-service", client.Instance.InstanceID, 5*time.Second)
+//	server.AssertStatus(t, "order-service", client.Instance.InstanceID, "UP")
+package eurekatest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+const (
+	// defaultDurationInSecs 实例未设置 LeaseInfo.DurationInSecs 时的租约时长，与 eureka 服务端一致
+	defaultDurationInSecs = 90
+	// deltaRetention 增量变更保留的时间，与 eureka 服务端一致
+	deltaRetention = 3 * time.Minute
+)
+
+// record 注册的实例与租约信息
+type record struct {
+	instance    eureka.Instance
+	lastRenewal time.Time
+	heartbeats  int
+}
+
+// change 增量变更
+type change struct {
+	time     time.Time
+	instance eureka.Instance
+}
+
+// Server 内存 eureka 服务端，实现注册、心跳、状态覆盖、注销以及全量与增量拉取服务列表
+// 应用名称与 eureka 服务端一样转为大写，服务端时间可以通过 Advance 推进以模拟租约过期
+type Server struct {
+	*httptest.Server
+
+	mutex   sync.Mutex
+	now     time.Time
+	apps    map[string]map[string]*record
+	changes []change
+	version int64
+}
+
+// NewServer 创建并启动服务端，使用完后需要调用 Close
+func NewServer() *Server {
+	s := &Server{
+		now:  time.Now(),
+		apps: make(map[string]map[string]*record),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eureka/", s.handle)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Zone 服务端地址，可以直接作为 Config.DefaultZone
+func (s *Server) Zone() string {
+	return s.URL + "/eureka/"
+}
+
+// Register 直接注册实例，不经过 HTTP，用于预置其他应用的实例
+func (s *Server) Register(instance eureka.Instance) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.register(instance)
+}
+
+// Instance 获取注册的实例
+func (s *Server) Instance(app, instanceID string) (eureka.Instance, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	r, ok := s.apps[appName(app)][instanceID]
+	if !ok {
+		return eureka.Instance{}, false
+	}
+	return copyInstance(r.instance), true
+}
+
+// Instances 获取应用注册的所有实例，按实例 ID 排序
+func (s *Server) Instances(app string) []eureka.Instance {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	records := s.apps[appName(app)]
+	instances := make([]eureka.Instance, 0, len(records))
+	for _, id := range sortedKeys(records) {
+		instances = append(instances, copyInstance(records[id].instance))
+	}
+	return instances
+}
+
+// Heartbeats 获取实例自注册以来收到的心跳次数，实例不存在时返回 0
+func (s *Server) Heartbeats(app, instanceID string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if r, ok := s.apps[appName(app)][instanceID]; ok {
+		return r.heartbeats
+	}
+	return 0
+}
+
+// Advance 推进服务端时间，并剔除租约已过期的实例，返回被剔除的实例 ID
+func (s *Server) Advance(d time.Duration) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.now = s.now.Add(d)
+	var evicted []string
+	for name, records := range s.apps {
+		for _, id := range sortedKeys(records) {
+			r := records[id]
+			duration := defaultDurationInSecs
+			if r.instance.LeaseInfo != nil && r.instance.LeaseInfo.DurationInSecs > 0 {
+				duration = r.instance.LeaseInfo.DurationInSecs
+			}
+			if s.now.Sub(r.lastRenewal) > time.Duration(duration)*time.Second {
+				s.remove(name, id)
+				evicted = append(evicted, id)
+			}
+		}
+	}
+	return evicted
+}
+
+// Evict 立即剔除实例，之后该实例的心跳会返回 404，用于模拟服务端丢失实例
+func (s *Server) Evict(app, instanceID string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.remove(appName(app), instanceID)
+}
+
+// Reset 清空所有实例与增量变更，用于模拟服务端重启
+func (s *Server) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.apps = make(map[string]map[string]*record)
+	s.changes = nil
+}
+
+// handle 处理 eureka REST 请求
+// https://github.com/Netflix/eureka/wiki/Eureka-REST-operations
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/eureka/"), "/"), "/")
+	if parts[0] != "apps" {
+		http.NotFound(w, r)
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		s.writeApplications(w, s.applications())
+	case len(parts) == 2 && parts[1] == "delta" && r.Method == http.MethodGet:
+		s.writeApplications(w, s.delta())
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		s.getApplication(w, parts[1])
+	case len(parts) == 2 && r.Method == http.MethodPost:
+		s.handleRegister(w, r, parts[1])
+	case len(parts) == 3 && r.Method == http.MethodPut:
+		s.handleHeartbeat(w, r, parts[1], parts[2])
+	case len(parts) == 3 && r.Method == http.MethodDelete:
+		s.handleUnRegister(w, parts[1], parts[2])
+	case len(parts) == 4 && parts[3] == "status" && (r.Method == http.MethodPut || r.Method == http.MethodDelete):
+		s.handleStatus(w, r, parts[1], parts[2])
+	default:
+		http.Error(w, "unsupported operation", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleRegister POST /eureka/apps/appID
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request, app string) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var instance eureka.Instance
+	if strings.Contains(r.Header.Get("Content-Type"), "xml") {
+		err = xml.Unmarshal(body, &instance)
+	} else {
+		info := struct {
+			Instance *eureka.Instance `json:"instance"`
+		}{Instance: &instance}
+		err = json.Unmarshal(body, &info)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if instance.InstanceID == "" {
+		instance.InstanceID = instance.HostName
+	}
+	instance.App = app
+	s.register(instance)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleHeartbeat PUT /eureka/apps/appID/instanceID
+func (s *Server) handleHeartbeat(w http.ResponseWriter, r *http.Request, app, instanceID string) {
+	record, ok := s.apps[appName(app)][instanceID]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	record.heartbeats++
+	record.lastRenewal = s.now
+	if record.instance.LeaseInfo != nil {
+		record.instance.LeaseInfo.LastRenewalTimestamp = millis(s.now)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleUnRegister DELETE /eureka/apps/appID/instanceID
+func (s *Server) handleUnRegister(w http.ResponseWriter, app, instanceID string) {
+	if !s.remove(appName(app), instanceID) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleStatus 覆盖实例状态 PUT /eureka/apps/appID/instanceID/status?value=OUT_OF_SERVICE，
+// 或删除覆盖的状态 DELETE /eureka/apps/appID/instanceID/status
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request, app, instanceID string) {
+	record, ok := s.apps[appName(app)][instanceID]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	status := r.URL.Query().Get("value")
+	if r.Method == http.MethodDelete {
+		status = "UP"
+		record.instance.OverriddenStatus = "UNKNOWN"
+	} else {
+		if status == "" {
+			http.Error(w, "missing value", http.StatusBadRequest)
+			return
+		}
+		record.instance.OverriddenStatus = status
+	}
+	record.instance.Status = status
+	s.modified(record.instance, "MODIFIED")
+	w.WriteHeader(http.StatusOK)
+}
+
+// getApplication GET /eureka/apps/appID
+func (s *Server) getApplication(w http.ResponseWriter, app string) {
+	records, ok := s.apps[appName(app)]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	application := eureka.Application{Name: appName(app)}
+	for _, id := range sortedKeys(records) {
+		application.Instances = append(application.Instances, records[id].instance)
+	}
+	w.Header().Set("Content-Type", eureka.ContentTypeJSON)
+	_ = json.NewEncoder(w).Encode(map[string]eureka.Application{"application": application})
+}
+
+// register 注册实例，调用时需持有 s.mutex
+func (s *Server) register(instance eureka.Instance) {
+	name := appName(instance.App)
+	instance.App = name
+	if instance.Status == "" {
+		instance.Status = "UP"
+	}
+	if instance.LeaseInfo == nil {
+		instance.LeaseInfo = &eureka.LeaseInfo{}
+	} else {
+		lease := *instance.LeaseInfo
+		instance.LeaseInfo = &lease
+	}
+	instance.LeaseInfo.RegistrationTimestamp = millis(s.now)
+	instance.LeaseInfo.LastRenewalTimestamp = millis(s.now)
+	instance.LeaseInfo.ServiceUpTimestamp = millis(s.now)
+	instance.LastUpdatedTimestamp = strconv.FormatInt(millis(s.now), 10)
+	if s.apps[name] == nil {
+		s.apps[name] = make(map[string]*record)
+	}
+	s.apps[name][instance.InstanceID] = &record{instance: instance, lastRenewal: s.now}
+	s.modified(instance, "ADDED")
+}
+
+// remove 删除实例，调用时需持有 s.mutex
+func (s *Server) remove(name, instanceID string) bool {
+	record, ok := s.apps[name][instanceID]
+	if !ok {
+		return false
+	}
+	delete(s.apps[name], instanceID)
+	if len(s.apps[name]) == 0 {
+		delete(s.apps, name)
+	}
+	s.modified(record.instance, "DELETED")
+	return true
+}
+
+// modified 记录增量变更，并丢弃超过保留时间的变更，调用时需持有 s.mutex
+func (s *Server) modified(instance eureka.Instance, actionType string) {
+	s.version++
+	instance = copyInstance(instance)
+	instance.ActionType = actionType
+	s.changes = append(s.changes, change{time: s.now, instance: instance})
+	for len(s.changes) > 0 && s.now.Sub(s.changes[0].time) > deltaRetention {
+		s.changes = s.changes[1:]
+	}
+}
+
+// applications 全量服务列表，调用时需持有 s.mutex
+func (s *Server) applications() *eureka.Applications {
+	apps := &eureka.Applications{VersionsDelta: strconv.FormatInt(s.version, 10)}
+	for _, name := range sortedKeys(s.apps) {
+		application := eureka.Application{Name: name}
+		for _, id := range sortedKeys(s.apps[name]) {
+			application.Instances = append(application.Instances, s.apps[name][id].instance)
+		}
+		apps.Applications = append(apps.Applications, application)
+	}
+	apps.AppsHashcode = hashcode(apps)
+	return apps
+}
+
+// delta 保留时间内的增量变更，哈希为全量服务列表的哈希，调用时需持有 s.mutex
+func (s *Server) delta() *eureka.Applications {
+	delta := &eureka.Applications{
+		VersionsDelta: strconv.FormatInt(s.version, 10),
+		AppsHashcode:  hashcode(s.applications()),
+	}
+	index := make(map[string]int)
+	for _, c := range s.changes {
+		if s.now.Sub(c.time) > deltaRetention {
+			continue
+		}
+		i, ok := index[c.instance.App]
+		if !ok {
+			i = len(delta.Applications)
+			index[c.instance.App] = i
+			delta.Applications = append(delta.Applications, eureka.Application{Name: c.instance.App})
+		}
+		delta.Applications[i].Instances = append(delta.Applications[i].Instances, c.instance)
+	}
+	return delta
+}
+
+// writeApplications 以 JSON 输出服务列表
+func (s *Server) writeApplications(w http.ResponseWriter, apps *eureka.Applications) {
+	w.Header().Set("Content-Type", eureka.ContentTypeJSON)
+	_ = json.NewEncoder(w).Encode(map[string]*eureka.Applications{"applications": apps})
+}
+
+// hashcode 与 eureka 服务端相同的服务列表哈希，格式为按状态排序的 STATUS_count_
+func hashcode(apps *eureka.Applications) string {
+	counts := make(map[string]int)
+	for _, app := range apps.Applications {
+		for _, instance := range app.Instances {
+			counts[instance.Status]++
+		}
+	}
+	hash := ""
+	for _, status := range sortedKeys(counts) {
+		hash += status + "_" + strconv.Itoa(counts[status]) + "_"
+	}
+	return hash
+}
+
+// appName eureka 服务端的应用名称为大写
+func appName(app string) string {
+	return strings.ToUpper(app)
+}
+
+// copyInstance 通过 JSON 深拷贝实例
+func copyInstance(instance eureka.Instance) eureka.Instance {
+	var c eureka.Instance
+	b, _ := json.Marshal(&instance)
+	_ = json.Unmarshal(b, &c)
+	return c
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -17,9 +17,9 @@ func main() {
 		RenewalIntervalInSecs:        10,
 		RegistryFetchIntervalSeconds: 15,
 		DurationInSecs:               30,
-		Metadata: map[string]interface{}{
+		InstanceMetadata: eureka.Metadata{
 			"VERSION":              "0.1.0",
-			"NODE_GROUP_ID":        0,
+			"NODE_GROUP_ID":        "0",
 			"PRODUCT_CODE":         "DEFAULT",
 			"PRODUCT_VERSION_CODE": "DEFAULT",
 			"PRODUCT_ENV_CODE":     "DEFAULT",
@@ -36,7 +36,7 @@ func main() {
 	// http server
 	http.HandleFunc("/v1/services", func(writer http.ResponseWriter, request *http.Request) {
 		// full applications from eureka server
-		apps := client.Applications
+		apps := client.GetApplications()
 
 		b, _ := json.Marshal(apps)
 		_, _ = writer.Write(b)
@@ -1,5 +1,3 @@
 module github.com/godoes/eureka-client
 
 go 1.18
-
-require golang.org/x/sync v0.7.0
@@ -0,0 +1,98 @@
+package eureka_client
+
+import (
+	"context"
+	"sync"
+)
+
+// 客户端后台 goroutine 名称
+//   - refresh：定时拉取服务列表，每个 Client 1 个
+//   - heartbeat：定时发送心跳，每个 Client 1 个
+//   - signal：监听退出信号，每个 Client 1 个
+//   - beat：BeatReactor 的调度 goroutine 1 个，以及按需启动的工作 goroutine，数量不超过 BeatReactor 的并发数
+const (
+	GoroutineRefresh   = "refresh"
+	GoroutineHeartbeat = "heartbeat"
+	GoroutineSignal    = "signal"
+	GoroutineBeat      = "beat"
+)
+
+// GoroutineReport 客户端后台 goroutine 统计
+type GoroutineReport struct {
+	// 各类后台任务当前运行的 goroutine 数量
+	Running map[string]int
+	// 当前运行的 goroutine 总数
+	Total int
+	// 所有 BeatReactor 同时发送心跳请求的上限之和
+	MaxConcurrentBeats int
+}
+
+// goroutineGroup 统计后台 goroutine 数量
+type goroutineGroup struct {
+	mutex   sync.Mutex
+	running map[string]int
+	wg      sync.WaitGroup
+}
+
+func newGoroutineGroup() *goroutineGroup {
+	return &goroutineGroup{running: make(map[string]int)}
+}
+
+// Go 启动名称为 name 的后台 goroutine
+func (g *goroutineGroup) Go(name string, fn func()) {
+	g.mutex.Lock()
+	if g.running == nil {
+		g.running = make(map[string]int)
+	}
+	g.running[name]++
+	g.mutex.Unlock()
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer func() {
+			g.mutex.Lock()
+			g.running[name]--
+			if g.running[name] == 0 {
+				delete(g.running, name)
+			}
+			g.mutex.Unlock()
+		}()
+		fn()
+	}()
+}
+
+// wait 等待通过 Go 启动的 goroutine 全部退出，ctx 结束时返回 ctx.Err()
+func (g *goroutineGroup) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// collect 将当前运行的 goroutine 数量累加到 report
+func (g *goroutineGroup) collect(report *GoroutineReport) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for name, n := range g.running {
+		report.Running[name] += n
+		report.Total += n
+	}
+}
+
+// GoroutineReport 获取客户端（包括 BeatReactor）当前运行的后台 goroutine 统计
+func (c *Client) GoroutineReport() GoroutineReport {
+	report := GoroutineReport{Running: make(map[string]int)}
+	c.goroutines.collect(&report)
+	for _, beater := range c.beaters() {
+		beater.goroutines.collect(&report)
+		report.MaxConcurrentBeats += beater.beatThreadCount
+	}
+	return report
+}
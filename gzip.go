@@ -0,0 +1,25 @@
+package eureka_client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// decompress 按响应的 Content-Encoding 解压响应内容
+// 手动设置 Accept-Encoding 时 http.Transport 不会自动解压
+func decompress(resp *http.Response, b []byte) ([]byte, error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return b, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,63 @@
+package eureka_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrNotRegistered 客户端未启动或实例尚未成功注册
+	ErrNotRegistered = errors.New("instance not registered")
+	// ErrLeaseExpired 最近一次成功续约已超过租约时长，服务端可能已剔除该实例
+	ErrLeaseExpired = errors.New("lease expired")
+)
+
+// HealthCheck 检查客户端与 eureka 服务端的连接是否健康
+// 已成功注册且最近一次成功续约（心跳或注册）在租约时长 DurationInSecs 内时返回 nil，
+// 否则返回 ErrNotRegistered 或 ErrLeaseExpired，可用于 Kubernetes 的存活与就绪探针
+func (c *Client) HealthCheck() error {
+	if state := c.lifecycle(); state != stateUp {
+		return fmt.Errorf("%w: client is %s", ErrNotRegistered, state)
+	}
+	last := c.Stats().LastSuccessfulRenewal
+	if last.IsZero() {
+		return ErrNotRegistered
+	}
+	lease := time.Duration(c.Config.DurationInSecs) * time.Second
+	if elapsed := c.Config.clock().Now().Sub(last); elapsed > lease {
+		return fmt.Errorf("%w: last renewal %s ago, lease %s", ErrLeaseExpired, elapsed.Truncate(time.Second), lease)
+	}
+	return nil
+}
+
+// HealthCheckContext 与 HealthCheck 相同，签名适配以 func(context.Context) error 作为检查函数的健康检查框架
+func (c *Client) HealthCheckContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.HealthCheck()
+}
+
+// healthStatus HealthHandler 的响应内容
+type healthStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthHandler 健康检查的 http.Handler，健康时返回 200，否则返回 503，响应内容为 JSON，
+// 可以直接作为 Kubernetes 的 httpGet 探针，例如挂载到 /health/eureka
+func (c *Client) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, code := healthStatus{Status: "UP"}, http.StatusOK
+		if err := c.HealthCheckContext(r.Context()); err != nil {
+			status, code = healthStatus{Status: "DOWN", Error: err.Error()}, http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
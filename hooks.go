@@ -0,0 +1,89 @@
+package eureka_client
+
+// LifecycleHooks 客户端生命周期事件的回调，未设置的回调忽略，可用于接入告警、功能开关与预热逻辑
+// 回调在客户端的后台 goroutine 中同步执行，耗时的操作应自行异步处理，回调 panic 时记录日志并继续执行
+type LifecycleHooks struct {
+	// 实例注册成功后调用，包括心跳返回 404、409 或连续失败后的重新注册
+	OnRegistered func(instance Instance)
+	// 实例通过 Deregister 注销成功后调用
+	OnDeregistered func(instance Instance)
+	// 心跳失败时调用，failures 为连续失败次数
+	OnHeartbeatError func(err error, failures int)
+	// 每次成功拉取服务列表后调用，apps 为服务列表的深拷贝，服务列表没有变化时也会调用
+	OnRegistryRefreshed func(apps *Applications)
+	// 实例对外的状态变化时调用，例如排空时由 UP 变为 OUT_OF_SERVICE
+	OnStatusChange func(oldStatus, newStatus string)
+}
+
+// RegisterHooks 添加生命周期事件的回调，可以多次调用，同一事件的回调按添加顺序执行
+func (c *Client) RegisterHooks(hooks LifecycleHooks) {
+	c.mutex.Lock()
+	c.hooks = append(c.hooks, hooks)
+	c.mutex.Unlock()
+}
+
+// fireHooks 对每组回调依次执行 fn，fn 中 panic 时记录日志并继续执行后面的回调
+func (c *Client) fireHooks(event string, fn func(hooks LifecycleHooks)) {
+	c.mutex.RLock()
+	all := append([]LifecycleHooks(nil), c.hooks...)
+	c.mutex.RUnlock()
+	for _, hooks := range all {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					c.logger.Errorf("%s hook panic: %v", event, r)
+				}
+			}()
+			fn(hooks)
+		}()
+	}
+}
+
+// onRegistered 执行实例注册成功的回调
+func (c *Client) onRegistered() {
+	c.fireHooks("OnRegistered", func(hooks LifecycleHooks) {
+		if hooks.OnRegistered != nil {
+			hooks.OnRegistered(c.Instance.snapshot())
+		}
+	})
+}
+
+// onDeregistered 执行实例注销成功的回调
+func (c *Client) onDeregistered() {
+	c.fireHooks("OnDeregistered", func(hooks LifecycleHooks) {
+		if hooks.OnDeregistered != nil {
+			hooks.OnDeregistered(c.Instance.snapshot())
+		}
+	})
+}
+
+// onHeartbeatError 执行心跳失败的回调
+func (c *Client) onHeartbeatError(err error) {
+	failures := c.stats.snapshot().ConsecutiveHeartbeatFailures
+	c.fireHooks("OnHeartbeatError", func(hooks LifecycleHooks) {
+		if hooks.OnHeartbeatError != nil {
+			hooks.OnHeartbeatError(err, failures)
+		}
+	})
+}
+
+// onRegistryRefreshed 执行成功拉取服务列表的回调
+func (c *Client) onRegistryRefreshed() {
+	c.fireHooks("OnRegistryRefreshed", func(hooks LifecycleHooks) {
+		if hooks.OnRegistryRefreshed != nil {
+			hooks.OnRegistryRefreshed(c.GetApplications())
+		}
+	})
+}
+
+// onStatusChange 执行实例状态变化的回调，状态没有变化时忽略
+func (c *Client) onStatusChange(oldStatus, newStatus string) {
+	if oldStatus == newStatus {
+		return
+	}
+	c.fireHooks("OnStatusChange", func(hooks LifecycleHooks) {
+		if hooks.OnStatusChange != nil {
+			hooks.OnStatusChange(oldStatus, newStatus)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package eureka_client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// hostNameLookupTimeout 反向解析注册 IP 的超时时间，避免 DNS 不可用时阻塞客户端创建
+const hostNameLookupTimeout = 2 * time.Second
+
+// resolveHostName 获取注册使用的主机名
+// 优先反向解析 ip 得到完整域名（FQDN），解析失败时使用操作系统的主机名，都获取不到时返回 ip
+func resolveHostName(ip string) string {
+	if ip != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), hostNameLookupTimeout)
+		names, err := net.DefaultResolver.LookupAddr(ctx, ip)
+		cancel()
+		if err == nil && len(names) > 0 {
+			return strings.TrimSuffix(names[0], ".")
+		}
+	}
+	if hostName, err := os.Hostname(); err == nil && hostName != "" {
+		return hostName
+	}
+	return ip
+}
+
+// urlHost 构建实例 url 使用的主机，PreferIPAddress 时为 IP，否则为 HostName
+func (c *Config) urlHost() string {
+	if c.PreferIPAddress || c.HostName == "" {
+		return c.IP
+	}
+	return c.HostName
+}
+
+// baseURL 实例 url 的前缀，启用安全端口时为 https://host:securePort，否则为 http://host:port
+func (c *Config) baseURL() string {
+	if c.SecurePortEnabled {
+		return fmt.Sprintf("https://%s:%d", c.urlHost(), c.SecurePort)
+	}
+	return fmt.Sprintf("http://%s:%d", c.urlHost(), c.Port)
+}
@@ -0,0 +1,41 @@
+package eureka_client
+
+import (
+	"net/http"
+	"sync"
+)
+
+var (
+	// defaultAPIClient eureka REST 调用默认使用的 http.Client
+	// DefaultZone 的域名解析为多个 IP 时，在这些 IP 之间轮询建立连接并避开最近连接失败的 IP
+	defaultAPIClient = &http.Client{Transport: newRoundRobinTransport()}
+
+	apiClient      = defaultAPIClient
+	apiClientMutex sync.RWMutex
+)
+
+// SetHTTPClient 设置 eureka REST 调用使用的 http.Client，为 nil 则恢复默认
+// 对包级别的函数（Register、Heartbeat、Refresh 等）以及没有设置 Config.HTTPClient 的客户端生效
+func SetHTTPClient(client *http.Client) {
+	if client == nil {
+		client = defaultAPIClient
+	}
+	apiClientMutex.Lock()
+	apiClient = client
+	apiClientMutex.Unlock()
+}
+
+// httpClient 获取 SetHTTPClient 设置的 http.Client
+func httpClient() *http.Client {
+	apiClientMutex.RLock()
+	defer apiClientMutex.RUnlock()
+	return apiClient
+}
+
+// httpClient 获取配置的 http.Client，未配置则使用 SetHTTPClient 设置的 http.Client
+func (c *Config) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return httpClient()
+}
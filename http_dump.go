@@ -0,0 +1,31 @@
+package eureka_client
+
+import (
+	"io"
+	"sync"
+
+	"github.com/godoes/eureka-client/requests"
+)
+
+var (
+	httpDump      requests.Middleware
+	httpDumpMutex sync.RWMutex
+)
+
+// SetHTTPDump 将 eureka REST 调用的请求与响应报文写入 w，用于调试，为 nil 则不输出
+// 报文包含请求与响应内容，拉取全量服务列表时内容较大，只建议在排查问题时开启
+func SetHTTPDump(w io.Writer) {
+	var dump requests.Middleware
+	if w != nil {
+		dump = requests.DumpMiddleware(w, true)
+	}
+	httpDumpMutex.Lock()
+	httpDump = dump
+	httpDumpMutex.Unlock()
+}
+
+func getHTTPDump() requests.Middleware {
+	httpDumpMutex.RLock()
+	defer httpDumpMutex.RUnlock()
+	return httpDump
+}
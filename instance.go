@@ -0,0 +1,90 @@
+package eureka_client
+
+import (
+	"context"
+	"sync"
+)
+
+// RegisteredInstance 客户端注册的服务实例
+// Instance 为发送给服务端的实例信息，Config 与 Beater 为客户端运行时使用的配置和发送心跳的 BeatReactor
+type RegisteredInstance struct {
+	*Instance
+	Config *Config
+	Beater *BeatReactor
+	// 保证同一实例的 SetStatus 依次执行
+	statusMutex sync.Mutex
+	// 实例的状态锁，Instance 通过 stateMutex 引用，见 Instance.stateLock
+	stateMutex sync.RWMutex
+	// 客户端自身的实例由 Client 的心跳循环发送心跳，注册时不加入 Beater，避免重复发送心跳
+	heartbeatByClient bool
+}
+
+// NewRegisteredInstance 根据配置创建需要注册的服务实例
+func NewRegisteredInstance(config *Config) *RegisteredInstance {
+	beater := NewBeatReactor(config, int64(config.RenewalIntervalInSecs))
+	ri := &RegisteredInstance{
+		Instance: NewInstance(config),
+		Config:   config,
+		Beater:   &beater,
+	}
+	ri.guard()
+	return ri
+}
+
+// guard 使 Instance 使用该 RegisteredInstance 的状态锁，直接构造的 RegisteredInstance 在首次注册或修改状态时设置，
+// 此时还没有后台 goroutine 读取实例状态
+func (ri *RegisteredInstance) guard() {
+	if ri.Instance.stateMutex == nil {
+		ri.Instance.stateMutex = &ri.stateMutex
+	}
+}
+
+// Register 注册实例，成功后开始发送心跳（客户端自身的实例由客户端的心跳循环发送），Config.Metadata 中存在无法转换为字符串的值时返回错误
+func (ri *RegisteredInstance) Register() error {
+	return ri.RegisterContext(context.Background())
+}
+
+// RegisterContext 注册实例，ctx 取消或超时时中止请求
+func (ri *RegisteredInstance) RegisterContext(ctx context.Context) error {
+	ri.guard()
+	if _, err := ConvertMetadata(ri.Config.Metadata); err != nil {
+		return err
+	}
+	if err := register(ctx, ri.Config.httpClient(), ri.Config.DefaultZone, ri.Config.App, ri.Instance, ri.Config.contentType()); err != nil {
+		return err
+	}
+	if !ri.heartbeatByClient {
+		ri.Beater.AddBeatInfo(ri.Instance)
+	}
+	return nil
+}
+
+// OutOfService 将实例状态覆盖为 OUT_OF_SERVICE，调用方拉取到服务列表后不再向该实例发送请求，心跳会继续发送
+func (ri *RegisteredInstance) OutOfService() error {
+	return ri.OutOfServiceContext(context.Background())
+}
+
+// OutOfServiceContext 将实例状态覆盖为 OUT_OF_SERVICE，ctx 取消或超时时中止请求
+func (ri *RegisteredInstance) OutOfServiceContext(ctx context.Context) error {
+	ri.guard()
+	if err := statusOverride(ctx, ri.Config.httpClient(), ri.Config.DefaultZone, ri.App, ri.InstanceID, "OUT_OF_SERVICE"); err != nil {
+		return err
+	}
+	// 心跳时带上覆盖的状态，避免服务端认为实例信息不一致
+	ri.Instance.setOverriddenStatus(StatusOutOfService)
+	return nil
+}
+
+// UnRegister 删除实例，成功后停止发送心跳
+func (ri *RegisteredInstance) UnRegister() error {
+	return ri.UnRegisterContext(context.Background())
+}
+
+// UnRegisterContext 删除实例，ctx 取消或超时时中止请求
+func (ri *RegisteredInstance) UnRegisterContext(ctx context.Context) error {
+	if err := unRegister(ctx, ri.Config.httpClient(), ri.Config.DefaultZone, ri.App, ri.Instance); err != nil {
+		return err
+	}
+	ri.Beater.RemoveBeatInfo(ri.App, ri.InstanceID)
+	return nil
+}
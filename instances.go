@@ -0,0 +1,121 @@
+package eureka_client
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AddInstance 使用同一个客户端注册另一个实例，例如同一进程中分别以不同应用注册的 HTTP 与 gRPC 端口
+// 未设置的 DefaultZone、心跳与过期间隔、ContentType、Clock、NameMapper 使用客户端的配置
+// 实例与客户端共用拉取服务列表的循环，注册成功后由按实例的配置创建的 BeatReactor 发送心跳，
+// 实例的 DefaultZone、ContentType、HTTPClient 与客户端不同时，心跳也发送到实例自己的服务端
+func (c *Client) AddInstance(config *Config) (*RegisteredInstance, error) {
+	c.inheritConfig(config)
+	DefaultConfig(config)
+	instance := NewRegisteredInstance(config)
+	instance.Beater.SetLogger(ComponentLogger(c.baseLogger, ComponentBeat))
+
+	c.mutex.Lock()
+	if _, ok := c.instances[instance.InstanceID]; ok || instance.InstanceID == c.Instance.InstanceID {
+		c.mutex.Unlock()
+		return nil, fmt.Errorf("instance %s already exists", instance.InstanceID)
+	}
+	if c.instances == nil {
+		c.instances = make(map[string]*RegisteredInstance)
+	}
+	c.instances[instance.InstanceID] = instance
+	c.mutex.Unlock()
+
+	if err := instance.Register(); err != nil {
+		c.mutex.Lock()
+		delete(c.instances, instance.InstanceID)
+		c.mutex.Unlock()
+		return nil, err
+	}
+	c.opLogger(OpRegister).Infof("register additional instance %s successful", instance.InstanceID)
+	return instance, nil
+}
+
+// RemoveInstance 删除通过 AddInstance 注册的实例，并停止其心跳
+func (c *Client) RemoveInstance(instanceID string) error {
+	return c.removeInstance(context.Background(), instanceID)
+}
+
+// removeInstance 删除通过 AddInstance 注册的实例，ctx 取消或超时时中止请求
+func (c *Client) removeInstance(ctx context.Context, instanceID string) error {
+	c.mutex.RLock()
+	instance, ok := c.instances[instanceID]
+	c.mutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: instance %s", ErrNotFound, instanceID)
+	}
+	if err := instance.UnRegisterContext(ctx); err != nil {
+		return err
+	}
+	instance.Beater.Stop()
+	c.mutex.Lock()
+	delete(c.instances, instanceID)
+	c.mutex.Unlock()
+	c.opLogger(OpUnRegister).Infof("de-register additional instance %s successful", instanceID)
+	return nil
+}
+
+// Instances 获取通过 AddInstance 注册的实例，不包括客户端自身的实例
+func (c *Client) Instances() []*RegisteredInstance {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	instances := make([]*RegisteredInstance, 0, len(c.instances))
+	for _, instance := range c.instances {
+		instances = append(instances, instance)
+	}
+	return instances
+}
+
+// beaters 客户端自身的实例与通过 AddInstance 注册的实例使用的 BeatReactor
+func (c *Client) beaters() []*BeatReactor {
+	var beaters []*BeatReactor
+	if c.Instance != nil && c.Instance.Beater != nil {
+		beaters = append(beaters, c.Instance.Beater)
+	}
+	for _, instance := range c.Instances() {
+		beaters = append(beaters, instance.Beater)
+	}
+	return beaters
+}
+
+// removeInstances 删除所有通过 AddInstance 注册的实例
+func (c *Client) removeInstances(ctx context.Context) {
+	for _, instance := range c.Instances() {
+		if err := c.removeInstance(ctx, instance.InstanceID); err != nil {
+			c.opLogger(OpUnRegister).Error("de-register additional instance failed", err)
+		}
+	}
+}
+
+// inheritConfig 未设置的公共配置使用客户端的配置
+func (c *Client) inheritConfig(config *Config) {
+	if config.DefaultZone == "" {
+		config.DefaultZone = c.Config.DefaultZone
+	}
+	if config.RenewalIntervalInSecs == 0 {
+		// 心跳间隔可能被 SetIntervals 修改，需要加锁读取
+		renewal, _ := c.intervals()
+		config.RenewalIntervalInSecs = int(renewal / time.Second)
+	}
+	if config.DurationInSecs == 0 {
+		config.DurationInSecs = c.Config.DurationInSecs
+	}
+	if config.ContentType == "" {
+		config.ContentType = c.Config.ContentType
+	}
+	if config.Clock == nil {
+		config.Clock = c.Config.Clock
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = c.Config.HTTPClient
+	}
+	if config.NameMapper == nil {
+		config.NameMapper = c.Config.NameMapper
+	}
+}
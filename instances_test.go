@@ -0,0 +1,31 @@
+package eureka_client_test
+
+import (
+	"testing"
+	"time"
+
+	eureka "github.com/godoes/eureka-client"
+	"github.com/godoes/eureka-client/eurekatest"
+)
+
+func TestAddInstanceHeartbeatsToOwnZone(t *testing.T) {
+	primary := eurekatest.NewServer()
+	defer primary.Close()
+	other := eurekatest.NewServer()
+	defer other.Close()
+
+	c := eureka.NewClient(&eureka.Config{DefaultZone: primary.Zone(), App: "main", Port: 8080, RenewalIntervalInSecs: 1})
+	c.Start()
+	defer c.Stop()
+	extra, err := c.AddInstance(&eureka.Config{DefaultZone: other.Zone(), App: "extra", Port: 9090})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !other.WaitHeartbeats("EXTRA", extra.InstanceID, 1, 3*time.Second) {
+		t.Fatal("no heartbeat of the added instance on its own zone")
+	}
+	if _, ok := primary.Instance("EXTRA", extra.InstanceID); ok {
+		t.Fatal("added instance registered on the primary zone")
+	}
+}
@@ -0,0 +1,61 @@
+package eureka_client
+
+import "time"
+
+// SetIntervals 调整运行中客户端的心跳间隔与拉取服务列表的间隔（秒），小于等于 0 表示保持不变
+// 心跳与刷新循环按新的间隔重新计时，心跳间隔同时应用于 AddInstance 注册的实例，不需要重启客户端，可以在故障期间降低对服务端的压力
+// 只影响客户端发送请求的频率，已注册的实例信息中的 LeaseInfo 不变
+func (c *Client) SetIntervals(renewalIntervalInSecs, registryFetchIntervalSeconds int) {
+	c.mutex.Lock()
+	if renewalIntervalInSecs > 0 {
+		c.Config.RenewalIntervalInSecs = renewalIntervalInSecs
+	}
+	if registryFetchIntervalSeconds > 0 {
+		c.Config.RegistryFetchIntervalSeconds = registryFetchIntervalSeconds
+	}
+	c.mutex.Unlock()
+
+	if renewalIntervalInSecs > 0 {
+		// 客户端自身的实例由心跳循环按新的间隔重新计时，AddInstance 注册的实例由各自的 BeatReactor 重新调度
+		for _, instance := range c.Instances() {
+			instance.Beater.SetPeriod(instance.InstanceID, time.Duration(renewalIntervalInSecs)*time.Second)
+		}
+		notify(c.heartbeatWakeup)
+	}
+	if registryFetchIntervalSeconds > 0 {
+		notify(c.refreshWakeup)
+	}
+}
+
+// Intervals 当前的心跳间隔与拉取服务列表的间隔，包括 SetIntervals 的调整，
+// 运行中的客户端需要读取间隔时使用，避免直接读取 Config 与 SetIntervals 产生数据竞争
+func (c *Client) Intervals() (renewal, fetch time.Duration) {
+	return c.intervals()
+}
+
+// intervals 当前的心跳间隔与拉取服务列表的间隔
+func (c *Client) intervals() (renewal, fetch time.Duration) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return time.Duration(c.Config.RenewalIntervalInSecs) * time.Second,
+		time.Duration(c.Config.RegistryFetchIntervalSeconds) * time.Second
+}
+
+// notify 非阻塞地唤醒等待 ch 的循环，已有未处理的通知时忽略
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// resetTimer 停止 timer 并清空未读取的到期事件后按 d 重新计时
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(d)
+}
@@ -0,0 +1,27 @@
+package eureka_client_test
+
+import (
+	"testing"
+	"time"
+
+	eureka "github.com/godoes/eureka-client"
+	"github.com/godoes/eureka-client/eurekatest"
+)
+
+func TestSetIntervalsAppliesToAddedInstances(t *testing.T) {
+	srv := eurekatest.NewServer()
+	defer srv.Close()
+	c := eureka.NewClient(&eureka.Config{DefaultZone: srv.Zone(), App: "main", Port: 8080, RenewalIntervalInSecs: 30})
+	c.Start()
+	defer c.Stop()
+	extra, err := c.AddInstance(&eureka.Config{App: "extra", Port: 9090})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetIntervals(1, 0)
+	// 按原来 30 秒的间隔只会发送第一次心跳
+	if !srv.WaitHeartbeats("EXTRA", extra.InstanceID, 3, 6*time.Second) {
+		t.Fatalf("heartbeats = %d, want the added instance to follow the new 1s interval", srv.Heartbeats("EXTRA", extra.InstanceID))
+	}
+}
@@ -0,0 +1,107 @@
+package eureka_client
+
+import (
+	"fmt"
+	"net"
+)
+
+// GetLocalIP 获取本地 ip，返回第一个非回环、非链路本地的 IPv4 地址
+func GetLocalIP() (ip string) {
+	addresses, err := net.InterfaceAddrs()
+	if err != nil {
+		return
+	}
+	if ips := usableIPv4s(addresses); len(ips) > 0 {
+		return ips[0].String()
+	}
+	return
+}
+
+// GetIPByInterface 获取网卡 name（例如 eth1）的第一个非回环、非链路本地的 IPv4 地址，用于多网卡主机选择注册的地址
+func GetIPByInterface(name string) (string, error) {
+	ips, err := interfaceIPv4s(name)
+	if err != nil {
+		return "", err
+	}
+	return ips[0].String(), nil
+}
+
+// interfaceIPv4s 获取网卡 name 的非回环、非链路本地的 IPv4 地址，没有时返回错误
+func interfaceIPv4s(name string) ([]net.IP, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	ips := usableIPv4s(addresses)
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no IPv4 address on network interface %s", name)
+	}
+	return ips, nil
+}
+
+// usableIPv4s 过滤出非回环、非链路本地的 IPv4 地址
+func usableIPv4s(addresses []net.Addr) []net.IP {
+	var ips []net.IP
+	for _, address := range addresses {
+		if ipNet, ok := address.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && !ipNet.IP.IsLinkLocalUnicast() {
+			if ip := ipNet.IP.To4(); ip != nil {
+				ips = append(ips, ip)
+			}
+		}
+	}
+	return ips
+}
+
+// parseNetworks 解析 CIDR 格式的网段，例如 10.2.0.0/16
+func parseNetworks(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid preferred network: %w", err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// preferIP 按 networks 的顺序返回第一个属于该网段的地址，都不属于时返回第一个地址
+func preferIP(ips []net.IP, networks []*net.IPNet) net.IP {
+	for _, network := range networks {
+		for _, ip := range ips {
+			if network.Contains(ip) {
+				return ip
+			}
+		}
+	}
+	return ips[0]
+}
+
+// detectIP 根据配置获取注册的 IP
+// 设置了 Config.NetworkInterface 时只考虑该网卡的地址，设置了 Config.PreferredNetworks 时优先选择属于这些网段的地址
+func detectIP(config *Config) (string, error) {
+	networks, err := parseNetworks(config.PreferredNetworks)
+	if err != nil {
+		return "", err
+	}
+	var ips []net.IP
+	if config.NetworkInterface != "" {
+		if ips, err = interfaceIPv4s(config.NetworkInterface); err != nil {
+			return "", err
+		}
+	} else {
+		addresses, err := net.InterfaceAddrs()
+		if err != nil {
+			return "", err
+		}
+		ips = usableIPv4s(addresses)
+	}
+	if len(ips) == 0 {
+		return "", nil
+	}
+	return preferIP(ips, networks).String(), nil
+}
@@ -0,0 +1,92 @@
+package eureka_client
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Kubernetes downward API 注入的环境变量，需要在 Pod 的 env 中通过 fieldRef 设置
+//
+//	env:
+//	  - name: POD_NAME
+//	    valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	  - name: POD_NAMESPACE
+//	    valueFrom: {fieldRef: {fieldPath: metadata.namespace}}
+//	  - name: POD_IP
+//	    valueFrom: {fieldRef: {fieldPath: status.podIP}}
+const (
+	EnvPodName      = "POD_NAME"
+	EnvPodNamespace = "POD_NAMESPACE"
+	EnvPodIP        = "POD_IP"
+)
+
+// preStopDeregisterReserve PreStop 为注销请求预留的时间，排空时间不超过期限减去该时间
+const preStopDeregisterReserve = 5 * time.Second
+
+// KubernetesIdentity 根据 downward API 注入的环境变量填充未设置的实例信息，返回 config 便于链式调用
+// InstanceID 取 POD_NAME，IP 与 HostName 取 POD_IP（Pod 名称通常无法被其他 Pod 解析），
+// 同时将 Pod 名称与命名空间写入元数据 k8s-pod-name、k8s-namespace，不在 Kubernetes 中运行时不做修改
+func KubernetesIdentity(config *Config) *Config {
+	podName, podIP, namespace := os.Getenv(EnvPodName), os.Getenv(EnvPodIP), os.Getenv(EnvPodNamespace)
+	if podName != "" && config.InstanceID == "" {
+		config.InstanceID = podName
+	}
+	if podIP != "" {
+		if config.IP == "" {
+			config.IP = podIP
+		}
+		if config.HostName == "" {
+			config.HostName = podIP
+		}
+	}
+	setMetadataDefault(config, "k8s-pod-name", podName)
+	setMetadataDefault(config, "k8s-namespace", namespace)
+	return config
+}
+
+// setMetadataDefault value 不为空且元数据中不存在 key 时写入 InstanceMetadata
+func setMetadataDefault(config *Config, key, value string) {
+	if value == "" {
+		return
+	}
+	if _, ok := config.InstanceMetadata[key]; ok {
+		return
+	}
+	if _, ok := config.Metadata[key]; ok {
+		return
+	}
+	if config.InstanceMetadata == nil {
+		config.InstanceMetadata = make(Metadata)
+	}
+	config.InstanceMetadata[key] = value
+}
+
+// PreStop 供 Kubernetes preStop 钩子调用：将实例置为 OUT_OF_SERVICE，等待排空后注销，整个过程不超过 timeout
+// 排空时间取 Config.DrainDurationInSecs，并且不超过 timeout 减去为注销请求预留的 5s，
+// 超过 timeout 时中止进行中的请求并返回错误，之后收到 SIGTERM 时会再次尝试注销；timeout 小于等于 0 表示不限制
+// 应小于 Pod 的 terminationGracePeriodSeconds，注销成功后收到的 SIGTERM 不会再次注销
+func (c *Client) PreStop(timeout time.Duration) error {
+	drain := time.Duration(c.Config.DrainDurationInSecs) * time.Second
+	if timeout <= 0 {
+		return c.deregister(context.Background(), true, drain)
+	}
+	if drain > timeout-preStopDeregisterReserve {
+		drain = timeout - preStopDeregisterReserve
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return c.deregister(ctx, true, drain)
+}
+
+// PreStopHandler 执行 PreStop 的 http.Handler，用于 preStop 的 httpGet 钩子，成功返回 200，失败返回 500
+func (c *Client) PreStopHandler(timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := c.PreStop(timeout); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
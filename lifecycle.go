@@ -0,0 +1,108 @@
+package eureka_client
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClientStopped 客户端已停止或正在停止，不能再次启动
+var ErrClientStopped = errors.New("client stopped")
+
+// lifecycleState 客户端的生命周期状态，只能按 NEW → STARTING → UP → STOPPING → STOPPED 的顺序变化，
+// 未启动的客户端可以直接停止
+type lifecycleState int
+
+const (
+	stateNew lifecycleState = iota
+	stateStarting
+	stateUp
+	stateStopping
+	stateStopped
+)
+
+var lifecycleStateNames = [...]string{"NEW", "STARTING", "UP", "STOPPING", "STOPPED"}
+
+func (s lifecycleState) String() string {
+	if s < 0 || int(s) >= len(lifecycleStateNames) {
+		return "UNKNOWN"
+	}
+	return lifecycleStateNames[s]
+}
+
+// lifecycle 获取客户端当前的生命周期状态
+func (c *Client) lifecycle() lifecycleState {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.state
+}
+
+// setLifecycle 修改客户端的生命周期状态，调用时需持有 c.lifecycleMutex
+func (c *Client) setLifecycle(state lifecycleState) {
+	c.mutex.Lock()
+	old := c.state
+	c.state = state
+	c.mutex.Unlock()
+	c.logger.Debugf("client lifecycle %s -> %s", old, state)
+	c.updateState()
+}
+
+// StartE 启动客户端，与 Start 相同，已启动时直接返回 nil，不会重复启动后台 goroutine，
+// 客户端已停止时返回 ErrClientStopped
+func (c *Client) StartE() error {
+	c.lifecycleMutex.Lock()
+	defer c.lifecycleMutex.Unlock()
+	switch c.lifecycle() {
+	case stateStarting, stateUp:
+		return nil
+	case stateStopping, stateStopped:
+		return ErrClientStopped
+	}
+
+	c.setLifecycle(stateStarting)
+	// 刷新服务列表
+	c.goroutines.Go(GoroutineRefresh, c.refresh)
+	// 心跳
+	c.goroutines.Go(GoroutineHeartbeat, c.heartbeat)
+	// 监听退出信号，自动删除注册信息
+	c.goroutines.Go(GoroutineSignal, c.handleSignal)
+	c.setLifecycle(stateUp)
+	return nil
+}
+
+// Stop 停止客户端，见 StopContext
+func (c *Client) Stop() error {
+	return c.StopContext(context.Background())
+}
+
+// StopContext 注销实例（设置了 Config.DrainDurationInSecs 时先排空），停止心跳与刷新服务列表，中止正在进行的后台请求，等待后台 goroutine 退出并关闭 Watch 的订阅
+// 未启动的客户端直接停止，重复调用返回 nil，停止后不能再次启动；注销失败时仍然停止并返回注销的错误，
+// ctx 取消或超时时中止注销与等待，返回 ctx 的错误
+func (c *Client) StopContext(ctx context.Context) error {
+	c.lifecycleMutex.Lock()
+	defer c.lifecycleMutex.Unlock()
+	switch c.lifecycle() {
+	case stateNew:
+		c.cancel()
+		c.closeWatchers()
+		c.setLifecycle(stateStopped)
+		return nil
+	case stateStopping, stateStopped:
+		return nil
+	}
+
+	c.setLifecycle(stateStopping)
+	err := c.DeregisterContext(ctx)
+	close(c.stop)
+	// 取消正在进行的后台请求，服务端无响应时不会一直等待
+	c.cancel()
+	for _, beater := range c.beaters() {
+		beater.Stop()
+	}
+	if waitErr := c.goroutines.wait(ctx); err == nil {
+		err = waitErr
+	}
+	// 在后台 goroutine 退出后关闭，不会再有通知
+	c.closeWatchers()
+	c.setLifecycle(stateStopped)
+	return err
+}
@@ -0,0 +1,16 @@
+module github.com/godoes/eureka-client/logadapter
+
+go 1.18
+
+require (
+	github.com/godoes/eureka-client v0.0.0
+	github.com/sirupsen/logrus v1.9.3
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
+
+replace github.com/godoes/eureka-client => ../
@@ -0,0 +1,63 @@
+package logadapter
+
+import (
+	"github.com/sirupsen/logrus"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+// LogrusLogger 基于 logrus 实现 eureka.LoggerV2
+type LogrusLogger struct {
+	logger logrus.FieldLogger
+}
+
+var _ eureka.LoggerV2 = (*LogrusLogger)(nil)
+
+// NewLogrusLogger 创建基于 logrus 的日志实现，logger 可以是 *logrus.Logger 或者 *logrus.Entry
+func NewLogrusLogger(logger logrus.FieldLogger) *LogrusLogger {
+	return &LogrusLogger{logger: logger}
+}
+
+func (l *LogrusLogger) Debug(msg string) {
+	l.logger.Debug(msg)
+}
+
+func (l *LogrusLogger) Info(msg string) {
+	l.logger.Info(msg)
+}
+
+func (l *LogrusLogger) Warn(msg string, err error) {
+	if err == nil {
+		l.logger.Warn(msg)
+		return
+	}
+	l.logger.WithError(err).Warn(msg)
+}
+
+func (l *LogrusLogger) Error(msg string, err error) {
+	if err == nil {
+		l.logger.Error(msg)
+		return
+	}
+	l.logger.WithError(err).Error(msg)
+}
+
+func (l *LogrusLogger) Debugf(format string, args ...interface{}) {
+	l.logger.Debugf(format, args...)
+}
+
+func (l *LogrusLogger) Infof(format string, args ...interface{}) {
+	l.logger.Infof(format, args...)
+}
+
+func (l *LogrusLogger) Warnf(format string, args ...interface{}) {
+	l.logger.Warnf(format, args...)
+}
+
+func (l *LogrusLogger) Errorf(format string, args ...interface{}) {
+	l.logger.Errorf(format, args...)
+}
+
+func (l *LogrusLogger) WithFields(fields eureka.Fields) eureka.LoggerV2 {
+	return &LogrusLogger{logger: l.logger.WithFields(logrus.Fields(fields))}
+}
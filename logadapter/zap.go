@@ -0,0 +1,67 @@
+package logadapter
+
+import (
+	"go.uber.org/zap"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+// ZapLogger 基于 zap 实现 eureka.LoggerV2
+type ZapLogger struct {
+	logger *zap.SugaredLogger
+}
+
+var _ eureka.LoggerV2 = (*ZapLogger)(nil)
+
+// NewZapLogger 创建基于 zap 的日志实现
+func NewZapLogger(logger *zap.Logger) *ZapLogger {
+	return &ZapLogger{logger: logger.Sugar()}
+}
+
+func (l *ZapLogger) Debug(msg string) {
+	l.logger.Debug(msg)
+}
+
+func (l *ZapLogger) Info(msg string) {
+	l.logger.Info(msg)
+}
+
+func (l *ZapLogger) Warn(msg string, err error) {
+	if err == nil {
+		l.logger.Warn(msg)
+		return
+	}
+	l.logger.Warnw(msg, zap.Error(err))
+}
+
+func (l *ZapLogger) Error(msg string, err error) {
+	if err == nil {
+		l.logger.Error(msg)
+		return
+	}
+	l.logger.Errorw(msg, zap.Error(err))
+}
+
+func (l *ZapLogger) Debugf(format string, args ...interface{}) {
+	l.logger.Debugf(format, args...)
+}
+
+func (l *ZapLogger) Infof(format string, args ...interface{}) {
+	l.logger.Infof(format, args...)
+}
+
+func (l *ZapLogger) Warnf(format string, args ...interface{}) {
+	l.logger.Warnf(format, args...)
+}
+
+func (l *ZapLogger) Errorf(format string, args ...interface{}) {
+	l.logger.Errorf(format, args...)
+}
+
+func (l *ZapLogger) WithFields(fields eureka.Fields) eureka.LoggerV2 {
+	args := make([]interface{}, 0, len(fields)*2)
+	for k, v := range fields {
+		args = append(args, k, v)
+	}
+	return &ZapLogger{logger: l.logger.With(args...)}
+}
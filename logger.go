@@ -1,6 +1,14 @@
 package eureka_client
 
-import "log"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
 
 type Logger interface {
 	Debug(msg string)
@@ -9,25 +17,339 @@ type Logger interface {
 	Error(msg string, err error)
 }
 
+// Fields 结构化日志字段
+type Fields map[string]interface{}
+
+// LoggerV2 支持格式化与结构化字段的日志接口
+type LoggerV2 interface {
+	Logger
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	// WithFields 返回附加了结构化字段的日志实现
+	WithFields(fields Fields) LoggerV2
+}
+
+// 日志组件名称，作为 component 字段区分客户端各部分的日志，便于过滤
+const (
+	// 客户端的注册、心跳、拉取服务列表与注销
+	ComponentClient = "client"
+	// BeatReactor 发送的实例心跳
+	ComponentBeat = "beat"
+	// eureka REST 调用的访问日志，见 NewAccessLogger
+	ComponentAPI = "api"
+	// eureka REST 调用的 http 报文，见 NewLogWriter 与 SetHTTPDump
+	ComponentRequests = "requests"
+)
+
+// ComponentLogger 返回附加了 component 字段的子日志，logger 不应已附加 component 字段
+func ComponentLogger(logger Logger, component string) LoggerV2 {
+	return AsLoggerV2(logger).WithFields(Fields{"component": component})
+}
+
+// NewLogWriter 返回将每次写入的内容以 Debug 级别输出到 component 子日志的 io.Writer，忽略只包含空白的写入
+// 例如 SetHTTPDump(NewLogWriter(client.Logger(), ComponentRequests)) 将 http 报文输出到日志
+func NewLogWriter(logger Logger, component string) io.Writer {
+	return logWriter{logger: ComponentLogger(logger, component)}
+}
+
+// logWriter 将写入的内容输出到日志
+type logWriter struct {
+	logger LoggerV2
+}
+
+func (w logWriter) Write(p []byte) (int, error) {
+	if msg := strings.TrimSpace(string(p)); msg != "" {
+		w.logger.Debug(msg)
+	}
+	return len(p), nil
+}
+
+// DefaultLogger 默认的日志实现
+// 通过 NewLogger 创建或零值时，Go 1.21 及以上版本输出到 slog.Default()，使用应用设置的 slog Handler 的格式与输出，
+// 日志级别对应 slog 的级别，结构化字段与错误作为属性；更早的版本输出到 log 包默认的 Logger
+// 通过 NewDefaultLogger 创建时按配置输出到指定的 io.Writer
 type DefaultLogger struct {
+	fields Fields
+	// 为 nil 则输出到 slog.Default()，Go 1.21 以下版本使用 log 包默认的 Logger
+	logger *log.Logger
+	color  bool
+}
+
+// DefaultLoggerOption DefaultLogger 配置
+type DefaultLoggerOption func(o *defaultLoggerOptions)
+
+type defaultLoggerOptions struct {
+	output    io.Writer
+	color     *bool
+	timestamp bool
+	caller    bool
+}
+
+// WithOutput 设置日志输出，默认 os.Stderr
+func WithOutput(w io.Writer) DefaultLoggerOption {
+	return func(o *defaultLoggerOptions) {
+		o.output = w
+	}
+}
+
+// WithColor 设置是否输出带颜色的日志级别，默认仅在输出为终端且未设置 NO_COLOR 环境变量时启用
+func WithColor(enabled bool) DefaultLoggerOption {
+	return func(o *defaultLoggerOptions) {
+		o.color = &enabled
+	}
+}
+
+// WithTimestamp 设置是否输出时间，默认启用
+func WithTimestamp(enabled bool) DefaultLoggerOption {
+	return func(o *defaultLoggerOptions) {
+		o.timestamp = enabled
+	}
+}
+
+// WithCaller 设置是否输出调用日志的文件与行号，默认不启用
+func WithCaller(enabled bool) DefaultLoggerOption {
+	return func(o *defaultLoggerOptions) {
+		o.caller = enabled
+	}
+}
+
+// NewDefaultLogger 创建可配置输出、颜色、时间与调用位置的 DefaultLogger
+func NewDefaultLogger(opts ...DefaultLoggerOption) *DefaultLogger {
+	o := &defaultLoggerOptions{
+		output:    os.Stderr,
+		timestamp: true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	flags := 0
+	if o.timestamp {
+		flags |= log.LstdFlags
+	}
+	if o.caller {
+		flags |= log.Lshortfile
+	}
+	color := isTerminal(o.output) && os.Getenv("NO_COLOR") == ""
+	if o.color != nil {
+		color = *o.color
+	}
+	return &DefaultLogger{
+		logger: log.New(o.output, "", flags),
+		color:  color,
+	}
+}
+
+// isTerminal 判断输出是否为终端
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// 日志级别
+const (
+	levelDebug = "DEBUG"
+	levelInfo  = "INFO"
+	levelWarn  = "WARN"
+	levelError = "ERROR"
+)
+
+// levelColors 日志级别的 ANSI 颜色
+var levelColors = map[string]string{
+	levelDebug: "\033[36m",
+	levelInfo:  "\033[32m",
+	levelWarn:  "\033[33m",
+	levelError: "\033[31m",
+}
+
+// output 输出日志，所有日志方法都直接调用 output，保证调用位置正确
+func (l *DefaultLogger) output(level, msg string, err error) {
+	logger := l.logger
+	if logger == nil {
+		if slogOutput(level, msg, err, l.fields) {
+			return
+		}
+		logger = log.Default()
+	}
+	tag := "[" + level + "]"
+	if l.color {
+		tag = levelColors[level] + tag + "\033[0m"
+	}
+	_ = logger.Output(3, tag+" "+formatError(msg, err)+formatFields(l.fields))
+}
+
+func (l *DefaultLogger) Debug(msg string) {
+	l.output(levelDebug, msg, nil)
+}
+
+func (l *DefaultLogger) Info(msg string) {
+	l.output(levelInfo, msg, nil)
+}
+
+func (l *DefaultLogger) Warn(msg string, err error) {
+	l.output(levelWarn, msg, err)
 }
 
-func (*DefaultLogger) Debug(msg string) {
-	log.Println(msg)
+func (l *DefaultLogger) Error(msg string, err error) {
+	l.output(levelError, msg, err)
 }
 
-func (*DefaultLogger) Info(msg string) {
-	log.Println(msg)
+func (l *DefaultLogger) Debugf(format string, args ...interface{}) {
+	l.output(levelDebug, fmt.Sprintf(format, args...), nil)
 }
 
-func (*DefaultLogger) Warn(msg string, err error) {
-	log.Printf("%s, error: %v", msg, err)
+func (l *DefaultLogger) Infof(format string, args ...interface{}) {
+	l.output(levelInfo, fmt.Sprintf(format, args...), nil)
 }
 
-func (*DefaultLogger) Error(msg string, err error) {
-	log.Printf("%s, error: %v", msg, err)
+func (l *DefaultLogger) Warnf(format string, args ...interface{}) {
+	l.output(levelWarn, fmt.Sprintf(format, args...), nil)
 }
 
+func (l *DefaultLogger) Errorf(format string, args ...interface{}) {
+	l.output(levelError, fmt.Sprintf(format, args...), nil)
+}
+
+func (l *DefaultLogger) WithFields(fields Fields) LoggerV2 {
+	return &DefaultLogger{
+		fields: mergeFields(l.fields, fields),
+		logger: l.logger,
+		color:  l.color,
+	}
+}
+
+// NewLogger 创建默认的日志实现，Go 1.21 及以上版本输出到 slog.Default()
 func NewLogger() Logger {
 	return &DefaultLogger{}
 }
+
+// newClientLogger 创建客户端默认的日志实现，附加 app、instanceId、zone 字段
+func newClientLogger(config *Config) LoggerV2 {
+	return AsLoggerV2(NewLogger()).WithFields(Fields{
+		"app":        config.App,
+		"instanceId": config.InstanceID,
+		"zone":       RedactURL(config.DefaultZone),
+	})
+}
+
+// AsLoggerV2 将 Logger 转换为 LoggerV2
+// 未实现 LoggerV2 的日志实现，格式化方法使用 fmt.Sprintf 生成消息，结构化字段以 key=value 的形式追加到消息末尾
+func AsLoggerV2(logger Logger) LoggerV2 {
+	if l, ok := logger.(LoggerV2); ok {
+		return l
+	}
+	return &loggerV2{logger: logger}
+}
+
+// loggerV2 为 Logger 实现 LoggerV2
+type loggerV2 struct {
+	logger Logger
+	fields Fields
+}
+
+func (l *loggerV2) Debug(msg string) {
+	l.logger.Debug(msg + formatFields(l.fields))
+}
+
+func (l *loggerV2) Info(msg string) {
+	l.logger.Info(msg + formatFields(l.fields))
+}
+
+func (l *loggerV2) Warn(msg string, err error) {
+	l.logger.Warn(msg+formatFields(l.fields), err)
+}
+
+func (l *loggerV2) Error(msg string, err error) {
+	l.logger.Error(msg+formatFields(l.fields), err)
+}
+
+func (l *loggerV2) Debugf(format string, args ...interface{}) {
+	l.Debug(fmt.Sprintf(format, args...))
+}
+
+func (l *loggerV2) Infof(format string, args ...interface{}) {
+	l.Info(fmt.Sprintf(format, args...))
+}
+
+func (l *loggerV2) Warnf(format string, args ...interface{}) {
+	if f, ok := l.logger.(formatLogger); ok {
+		f.Warnf("%s", fmt.Sprintf(format, args...)+formatFields(l.fields))
+		return
+	}
+	l.Warn(splitError(format, args))
+}
+
+func (l *loggerV2) Errorf(format string, args ...interface{}) {
+	if f, ok := l.logger.(formatLogger); ok {
+		f.Errorf("%s", fmt.Sprintf(format, args...)+formatFields(l.fields))
+		return
+	}
+	l.Error(splitError(format, args))
+}
+
+func (l *loggerV2) WithFields(fields Fields) LoggerV2 {
+	return &loggerV2{logger: l.logger, fields: mergeFields(l.fields, fields)}
+}
+
+// formatLogger 只实现了格式化方法、没有实现 WithFields 的日志，Warnf 与 Errorf 不带错误输出
+type formatLogger interface {
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// splitError 格式化消息，参数中的最后一个 error 作为日志的错误；
+// 没有 error 参数时以消息本身作为错误，不向 Logger.Warn 与 Logger.Error 传入 nil
+func splitError(format string, args []interface{}) (string, error) {
+	msg := fmt.Sprintf(format, args...)
+	for i := len(args) - 1; i >= 0; i-- {
+		if err, ok := args[i].(error); ok && err != nil {
+			return msg, err
+		}
+	}
+	return msg, errors.New(msg)
+}
+
+// formatError 拼接消息与错误，err 为 nil 则只返回消息
+func formatError(msg string, err error) string {
+	if err == nil {
+		return msg
+	}
+	return fmt.Sprintf("%s, error: %v", msg, err)
+}
+
+// formatFields 将结构化字段按 key 排序格式化为 " key=value" 的形式
+func formatFields(fields Fields) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(&b, " %s=%v", k, fields[k])
+	}
+	return b.String()
+}
+
+// mergeFields 合并结构化字段，后者覆盖前者
+func mergeFields(fields, other Fields) Fields {
+	merged := make(Fields, len(fields)+len(other))
+	for k, v := range fields {
+		merged[k] = v
+	}
+	for k, v := range other {
+		merged[k] = v
+	}
+	return merged
+}
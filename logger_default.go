@@ -0,0 +1,8 @@
+//go:build !go1.21
+
+package eureka_client
+
+// slogOutput Go 1.21 以下版本没有 log/slog，DefaultLogger 输出到 log 包默认的 Logger
+func slogOutput(string, string, error, Fields) bool {
+	return false
+}
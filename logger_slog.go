@@ -0,0 +1,113 @@
+//go:build go1.21
+
+package eureka_client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// SlogLogger 基于 log/slog 的结构化日志实现，输出到指定的 slog.Logger
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger 创建基于 slog 的日志实现，logger 为 nil 则使用 slog.Default()
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogLogger{logger: logger}
+}
+
+// With 返回附加了结构化字段的日志实现，参数同 slog.Logger.With
+func (l *SlogLogger) With(args ...any) LoggerV2 {
+	return &SlogLogger{logger: l.logger.With(args...)}
+}
+
+func (l *SlogLogger) Debug(msg string) {
+	l.logger.Debug(msg)
+}
+
+func (l *SlogLogger) Info(msg string) {
+	l.logger.Info(msg)
+}
+
+func (l *SlogLogger) Warn(msg string, err error) {
+	if err == nil {
+		l.logger.Warn(msg)
+		return
+	}
+	l.logger.Warn(msg, "error", err)
+}
+
+func (l *SlogLogger) Error(msg string, err error) {
+	if err == nil {
+		l.logger.Error(msg)
+		return
+	}
+	l.logger.Error(msg, "error", err)
+}
+
+func (l *SlogLogger) Debugf(format string, args ...any) {
+	l.logger.Debug(fmt.Sprintf(format, args...))
+}
+
+func (l *SlogLogger) Infof(format string, args ...any) {
+	l.logger.Info(fmt.Sprintf(format, args...))
+}
+
+func (l *SlogLogger) Warnf(format string, args ...any) {
+	l.logger.Warn(fmt.Sprintf(format, args...))
+}
+
+func (l *SlogLogger) Errorf(format string, args ...any) {
+	l.logger.Error(fmt.Sprintf(format, args...))
+}
+
+func (l *SlogLogger) WithFields(fields Fields) LoggerV2 {
+	args := make([]any, 0, len(fields)*2)
+	for k, v := range fields {
+		args = append(args, k, v)
+	}
+	return l.With(args...)
+}
+
+// slogLevels DefaultLogger 的日志级别对应的 slog 级别
+var slogLevels = map[string]slog.Level{
+	levelDebug: slog.LevelDebug,
+	levelInfo:  slog.LevelInfo,
+	levelWarn:  slog.LevelWarn,
+	levelError: slog.LevelError,
+}
+
+// slogOutput 未配置输出的 DefaultLogger 通过 slog.Default() 输出，结构化字段按 key 排序后与错误一起作为属性
+// 记录调用 DefaultLogger 日志方法的位置，Handler 开启 AddSource 时输出正确的文件与行号
+func slogOutput(level, msg string, err error, fields Fields) bool {
+	logger := slog.Default()
+	ctx := context.Background()
+	if !logger.Enabled(ctx, slogLevels[level]) {
+		return true
+	}
+	var pcs [1]uintptr
+	// 跳过 runtime.Callers、slogOutput、DefaultLogger.output 与日志方法
+	runtime.Callers(4, pcs[:])
+	record := slog.NewRecord(time.Now(), slogLevels[level], msg, pcs[0])
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		record.AddAttrs(slog.Any(k, fields[k]))
+	}
+	if err != nil {
+		record.AddAttrs(slog.Any("error", err))
+	}
+	_ = logger.Handler().Handle(ctx, record)
+	return true
+}
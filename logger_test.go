@@ -0,0 +1,69 @@
+package eureka_client_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	eureka "github.com/godoes/eureka-client"
+)
+
+// recordLogger 只实现 Logger，记录 Warn 与 Error 收到的消息与错误
+type recordLogger struct {
+	msg string
+	err error
+}
+
+func (l *recordLogger) Debug(msg string) {}
+func (l *recordLogger) Info(msg string)  {}
+func (l *recordLogger) Warn(msg string, err error) {
+	l.msg, l.err = msg, err
+}
+func (l *recordLogger) Error(msg string, err error) {
+	l.msg, l.err = msg, err
+}
+
+// formatRecordLogger 额外实现了 Warnf 与 Errorf
+type formatRecordLogger struct {
+	recordLogger
+	formatted string
+}
+
+func (l *formatRecordLogger) Warnf(format string, args ...interface{}) {
+	l.formatted = fmt.Sprintf(format, args...)
+}
+func (l *formatRecordLogger) Errorf(format string, args ...interface{}) {
+	l.formatted = fmt.Sprintf(format, args...)
+}
+
+func TestLoggerV2FormatWithoutError(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		name    string
+		log     func(l eureka.LoggerV2)
+		wantMsg string
+		wantErr string
+	}{
+		{"warnf", func(l eureka.LoggerV2) { l.Warnf("hook panic: %v", "x") }, "hook panic: x", "hook panic: x"},
+		{"errorf", func(l eureka.LoggerV2) { l.Errorf("hook panic: %v", "x") }, "hook panic: x", "hook panic: x"},
+		{"error argument", func(l eureka.LoggerV2) { l.Warnf("failed %d times: %v", 3, boom) }, "failed 3 times: boom", "boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &recordLogger{}
+			tt.log(eureka.AsLoggerV2(l))
+			if l.err == nil {
+				t.Fatal("nil error passed to Logger")
+			}
+			if l.msg != tt.wantMsg || l.err.Error() != tt.wantErr {
+				t.Fatalf("msg = %q, err = %q, want %q, %q", l.msg, l.err, tt.wantMsg, tt.wantErr)
+			}
+
+			f := &formatRecordLogger{}
+			tt.log(eureka.AsLoggerV2(f).WithFields(eureka.Fields{"k": "v"}))
+			if f.err != nil || f.formatted != tt.wantMsg+" k=v" {
+				t.Fatalf("formatted = %q, err = %v, want %q without error", f.formatted, f.err, tt.wantMsg+" k=v")
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package eureka_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Metadata 实例元数据，eureka 要求元数据的值都是字符串
+type Metadata map[string]string
+
+// Get 获取元数据的值，不存在则返回空字符串
+func (m Metadata) Get(key string) string {
+	return m[key]
+}
+
+// ConvertMetadata 将 map[string]interface{} 类型的元数据转换为 Metadata
+// 字符串、布尔值、数值与 fmt.Stringer 转换为字符串，nil 转换为空字符串，其它类型无法转换，返回的错误中包含这些 key
+// 返回的 Metadata 中包含所有可以转换的值
+func ConvertMetadata(legacy map[string]interface{}) (Metadata, error) {
+	if legacy == nil {
+		return nil, nil
+	}
+	metadata := make(Metadata, len(legacy))
+	var invalid []string
+	for k, v := range legacy {
+		s, ok := metadataString(v)
+		if !ok {
+			invalid = append(invalid, fmt.Sprintf("%s (%T)", k, v))
+			continue
+		}
+		metadata[k] = s
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return metadata, fmt.Errorf("metadata values must be strings, unsupported: %v", invalid)
+	}
+	return metadata, nil
+}
+
+// metadataString 将元数据的值转换为字符串
+func metadataString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case json.Number:
+		return v.String(), true
+	case fmt.Stringer:
+		return v.String(), true
+	default:
+		return "", false
+	}
+}
+
+// UnmarshalJSON 兼容服务端返回的非字符串值，数值与布尔值转换为字符串，对象与数组保留原始的 JSON
+func (m *Metadata) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*m = nil
+		return nil
+	}
+	metadata := make(Metadata, len(raw))
+	for k, v := range raw {
+		decoder := json.NewDecoder(bytes.NewReader(v))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		if s, ok := metadataString(value); ok {
+			metadata[k] = s
+		} else {
+			metadata[k] = string(v)
+		}
+	}
+	*m = metadata
+	return nil
+}
+
+// instanceMetadata 合并 Metadata 与 InstanceMetadata，相同的 key 以 InstanceMetadata 为准，无法转换的值被忽略
+func (c *Config) instanceMetadata() Metadata {
+	metadata, _ := ConvertMetadata(c.Metadata)
+	if len(c.InstanceMetadata) > 0 && metadata == nil {
+		metadata = make(Metadata, len(c.InstanceMetadata))
+	}
+	for k, v := range c.InstanceMetadata {
+		metadata[k] = v
+	}
+	return metadata
+}
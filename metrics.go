@@ -0,0 +1,24 @@
+package eureka_client
+
+import "time"
+
+// Metrics 客户端运行指标的观察者，通过 Client.SetMetrics 设置
+type Metrics interface {
+	// HeartbeatCompleted 每次心跳结束时调用，err 为 nil 表示成功
+	HeartbeatCompleted(err error)
+	// RegistryFetched 每次拉取服务列表结束时调用，失败时 apps 为 nil
+	RegistryFetched(apps *Applications, duration time.Duration, err error)
+}
+
+// ReRegisterMetrics Metrics 可以同时实现该接口，心跳连续失败达到 Config.ReRegisterAfterFailures 触发重新注册后调用
+// failures 为连续失败次数，err 为重新注册的结果
+type ReRegisterMetrics interface {
+	ReRegistered(failures int, err error)
+}
+
+// nopMetrics 默认不记录任何指标
+type nopMetrics struct{}
+
+func (nopMetrics) HeartbeatCompleted(error) {}
+
+func (nopMetrics) RegistryFetched(*Applications, time.Duration, error) {}
@@ -0,0 +1,16 @@
+package eureka_client
+
+import "strings"
+
+// NameMapper 应用名称转换，注册和查询服务实例时都会使用，便于不同语言的命名风格互通
+type NameMapper func(name string) string
+
+// UpperCaseNameMapper 转为大写，与 eureka 服务端保存的应用名称保持一致
+func UpperCaseNameMapper(name string) string {
+	return strings.ToUpper(name)
+}
+
+// JavaNameMapper 将 go 风格的 order.service 转为 java 风格的 ORDER-SERVICE
+func JavaNameMapper(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, ".", "-"))
+}
@@ -0,0 +1,68 @@
+package eureka_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Int 获取端口号，p 为 nil 时返回 0
+func (p *Port) Int() int {
+	if p == nil {
+		return 0
+	}
+	return p.Port
+}
+
+// IsEnabled 端口是否启用
+func (p *Port) IsEnabled() bool {
+	return p != nil && strings.EqualFold(p.Enabled, "true")
+}
+
+// UnmarshalJSON 兼容不同服务端返回的端口格式，"$" 可以是数字或字符串，"@enabled" 可以是布尔值或字符串
+func (p *Port) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Port    json.RawMessage `json:"$"`
+		Enabled json.RawMessage `json:"@enabled"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	port, err := jsonScalar(raw.Port)
+	if err != nil {
+		return fmt.Errorf("invalid port: %w", err)
+	}
+	p.Port = 0
+	if port != "" {
+		if p.Port, err = strconv.Atoi(port); err != nil {
+			return fmt.Errorf("invalid port: %w", err)
+		}
+	}
+	if p.Enabled, err = jsonScalar(raw.Enabled); err != nil {
+		return fmt.Errorf("invalid port enabled: %w", err)
+	}
+	return nil
+}
+
+// jsonScalar 将 JSON 字符串、数字或布尔值转换为字符串，null 或不存在时返回空字符串
+func jsonScalar(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	if raw[0] == '"' {
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return strings.TrimSpace(s), err
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", err
+	}
+	switch v.(type) {
+	case float64, bool:
+		return string(raw), nil
+	default:
+		return "", fmt.Errorf("unexpected value %s", raw)
+	}
+}
@@ -0,0 +1,54 @@
+package eureka_client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+)
+
+// NewReverseProxy 创建 API 网关反向代理，将请求路径的第一段作为应用名称进行转发，例如：
+// /order-service/api/orders -> order-service 某个 UP 状态实例的 /api/orders
+// 连接失败时由 Transport 换实例重试
+func NewReverseProxy(client *Client) *httputil.ReverseProxy {
+	return newReverseProxy(client, func(req *http.Request) {
+		path := strings.TrimPrefix(req.URL.Path, "/")
+		app, rest, _ := strings.Cut(path, "/")
+		rewriteProxyURL(req, app, "/"+rest)
+	})
+}
+
+// NewSingleAppReverseProxy 创建反向代理，将所有请求原样转发到 app 的某个 UP 状态实例
+func NewSingleAppReverseProxy(client *Client, app string) *httputil.ReverseProxy {
+	return newReverseProxy(client, func(req *http.Request) {
+		rewriteProxyURL(req, app, req.URL.Path)
+	})
+}
+
+func newReverseProxy(client *Client, director func(req *http.Request)) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Director:  director,
+		Transport: NewTransport(client, nil),
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			client.logger.WithFields(Fields{"target": req.URL.Host}).Warn("reverse proxy failed", err)
+			if errors.Is(err, ErrNoInstance) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+}
+
+// rewriteProxyURL 将请求改写为 eureka://app/path，由 Transport 选择实例
+func rewriteProxyURL(req *http.Request, app, path string) {
+	req.URL.Scheme = Scheme
+	req.URL.Host = app
+	req.URL.Path = path
+	req.URL.RawPath = ""
+	req.Host = ""
+	if _, ok := req.Header["User-Agent"]; !ok {
+		// 与 httputil.NewSingleHostReverseProxy 保持一致，不使用默认的 User-Agent
+		req.Header.Set("User-Agent", "")
+	}
+}
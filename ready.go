@@ -0,0 +1,100 @@
+package eureka_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RequiredAppsPolicy 依赖应用在启动期限内没有 UP 实例时的处理策略
+type RequiredAppsPolicy int
+
+const (
+	// RequiredAppsFail StartAndWaitReady 返回错误
+	RequiredAppsFail RequiredAppsPolicy = iota
+	// RequiredAppsDegrade 记录警告日志，StartAndWaitReady 不返回错误
+	RequiredAppsDegrade
+)
+
+var (
+	// ErrRequiredAppsUnavailable 依赖应用在启动期限内没有 UP 实例
+	ErrRequiredAppsUnavailable = errors.New("required apps unavailable")
+)
+
+// StartAndWaitReady 启动客户端，并等待 Config.RequiredApps 中的应用都存在 UP 状态的实例
+// 超过 timeout 仍有依赖应用不可用时按 Config.RequiredAppsPolicy 处理，客户端已停止时返回 ErrClientStopped
+func (c *Client) StartAndWaitReady(timeout time.Duration) error {
+	if err := c.StartE(); err != nil {
+		return err
+	}
+
+	// 所有依赖应用共用同一个期限，依次等待即可
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, app := range c.Config.RequiredApps {
+		if _, err := c.WaitForInstancesContext(ctx, app); err != nil {
+			if errors.Is(err, ErrClientStopped) {
+				return err
+			}
+			break
+		}
+	}
+
+	missing := c.missingRequiredApps()
+	if len(missing) == 0 {
+		return nil
+	}
+	err := fmt.Errorf("%w: %s", ErrRequiredAppsUnavailable, strings.Join(missing, ", "))
+	if c.Config.RequiredAppsPolicy == RequiredAppsDegrade {
+		c.logger.Warn("start in degraded mode", err)
+		return nil
+	}
+	return err
+}
+
+// missingRequiredApps 获取没有 UP 实例的依赖应用
+func (c *Client) missingRequiredApps() []string {
+	missing := make([]string, 0)
+	for _, app := range c.Config.RequiredApps {
+		if len(c.GetInstances(app)) == 0 {
+			missing = append(missing, app)
+		}
+	}
+	return missing
+}
+
+// WaitForInstances 等待应用出现 UP 状态的实例，返回这些实例
+// 超过 timeout 仍没有 UP 实例时返回 ErrNoInstance，客户端停止时返回 ErrClientStopped，需要在 Start 之后调用
+func (c *Client) WaitForInstances(app string, timeout time.Duration) ([]Instance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	instances, err := c.WaitForInstancesContext(ctx, app)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w: %s", ErrNoInstance, app)
+	}
+	return instances, err
+}
+
+// WaitForInstancesContext 等待应用出现 UP 状态的实例，返回这些实例
+// 通过 Watch 在服务列表刷新后检查，ctx 取消或超时时返回 ctx.Err()，客户端停止时返回 ErrClientStopped
+func (c *Client) WaitForInstancesContext(ctx context.Context, app string) ([]Instance, error) {
+	changes, stop := c.Watch(app)
+	defer stop()
+	for {
+		select {
+		case instances, ok := <-changes:
+			if !ok {
+				return nil, ErrClientStopped
+			}
+			if len(instances) > 0 {
+				return instances, nil
+			}
+		case <-c.stop:
+			return nil, ErrClientStopped
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
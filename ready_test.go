@@ -0,0 +1,48 @@
+package eureka_client_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	eureka "github.com/godoes/eureka-client"
+	"github.com/godoes/eureka-client/eurekatest"
+)
+
+func TestWaitForInstances(t *testing.T) {
+	tests := []struct {
+		name    string
+		trigger func(srv *eurekatest.Server, c *eureka.Client)
+		wantErr error
+	}{
+		{"refreshed", func(srv *eurekatest.Server, c *eureka.Client) {
+			srv.Register(newTestInstance("svc-1", "10.0.0.1"))
+			_, _ = c.RefreshNow()
+		}, nil},
+		{"stopped", func(srv *eurekatest.Server, c *eureka.Client) { c.Stop() }, eureka.ErrClientStopped},
+		{"timeout", func(srv *eurekatest.Server, c *eureka.Client) {}, eureka.ErrNoInstance},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := eurekatest.NewServer()
+			defer srv.Close()
+			c := eureka.NewClient(&eureka.Config{DefaultZone: srv.Zone(), App: "me", Port: 1})
+			c.Start()
+			defer c.Stop()
+
+			time.AfterFunc(200*time.Millisecond, func() { tt.trigger(srv, c) })
+			start := time.Now()
+			instances, err := c.WaitForInstances("svc", time.Second)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && len(instances) != 1 {
+				t.Fatalf("instances = %+v, want 1 instance", instances)
+			}
+			// 除超时外都应在触发后立即返回，而不是等到超时
+			if tt.wantErr != eureka.ErrNoInstance && time.Since(start) > 800*time.Millisecond {
+				t.Fatalf("returned after %s", time.Since(start))
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package eureka_client
+
+// DefaultRegion 默认的本地区域，与 Java 客户端一致
+const DefaultRegion = "us-east-1"
+
+// instanceRegion 根据实例数据中心的 availability-zone 与 Config.AvailabilityZones 判断实例所在的区域，无法判断时为本地区域
+func (c *Config) instanceRegion(instance *Instance) string {
+	if instance.DataCenterInfo != nil && instance.DataCenterInfo.Metadata != nil {
+		zone := instance.DataCenterInfo.Metadata.AvailabilityZone
+		for region, zones := range c.AvailabilityZones {
+			for _, z := range zones {
+				if z == zone {
+					return region
+				}
+			}
+		}
+	}
+	if c.Region == "" {
+		return DefaultRegion
+	}
+	return c.Region
+}
+
+// GetRegionApplications 获取缓存的服务列表中指定区域的应用（深拷贝），远程区域需要配置 Config.FetchRemoteRegionsRegistry
+func (c *Client) GetRegionApplications(region string) *Applications {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.Applications == nil {
+		return nil
+	}
+	apps := &Applications{
+		VersionsDelta: c.Applications.VersionsDelta,
+		AppsHashcode:  c.Applications.AppsHashcode,
+	}
+	for _, app := range c.Applications.Applications {
+		instances := make([]Instance, 0)
+		for i := range app.Instances {
+			if c.Config.instanceRegion(&app.Instances[i]) == region {
+				instances = append(instances, app.Instances[i].deepCopy())
+			}
+		}
+		if len(instances) > 0 {
+			apps.Applications = append(apps.Applications, Application{Name: app.Name, Instances: instances})
+		}
+	}
+	return apps
+}
@@ -0,0 +1,124 @@
+package eureka_client
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RegistryDiff 一次刷新服务列表前后的实例变化
+type RegistryDiff struct {
+	// 新出现的实例
+	Added []Instance
+	// 消失的实例
+	Removed []Instance
+	// 状态、地址、端口或元数据等发生变化的实例，为变化后的实例
+	Changed []Instance
+}
+
+// RegistryListener 服务列表发生变化时执行的回调，diff 不为空
+type RegistryListener func(diff RegistryDiff)
+
+// Empty 服务列表是否没有变化
+func (d RegistryDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String 按应用汇总变化的实例数，例如 "+2 ORDER-SERVICE, -1 PAYMENT-SERVICE, ~1 USER-SERVICE"
+func (d RegistryDiff) String() string {
+	var parts []string
+	for _, group := range []struct {
+		sign      string
+		instances []Instance
+	}{{"+", d.Added}, {"-", d.Removed}, {"~", d.Changed}} {
+		counts := make(map[string]int)
+		for _, instance := range group.instances {
+			counts[instance.App]++
+		}
+		apps := make([]string, 0, len(counts))
+		for app := range counts {
+			apps = append(apps, app)
+		}
+		sort.Strings(apps)
+		for _, app := range apps {
+			parts = append(parts, fmt.Sprintf("%s%d %s", group.sign, counts[app], app))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// diffRegistry 对比新旧服务列表，old 为 nil 时所有实例都是新出现的
+func diffRegistry(old, current *Applications) RegistryDiff {
+	var diff RegistryDiff
+	previous := make(map[string]Instance)
+	if old != nil {
+		for _, app := range old.Applications {
+			for _, instance := range app.Instances {
+				previous[app.Name+"/"+instance.InstanceID] = instance
+			}
+		}
+	}
+	if current != nil {
+		for _, app := range current.Applications {
+			for _, instance := range app.Instances {
+				key := app.Name + "/" + instance.InstanceID
+				before, ok := previous[key]
+				if !ok {
+					diff.Added = append(diff.Added, instance)
+					continue
+				}
+				delete(previous, key)
+				if instanceChanged(before, instance) {
+					diff.Changed = append(diff.Changed, instance)
+				}
+			}
+		}
+	}
+	for _, instance := range previous {
+		diff.Removed = append(diff.Removed, instance)
+	}
+	sort.Slice(diff.Removed, func(i, j int) bool {
+		if diff.Removed[i].App != diff.Removed[j].App {
+			return diff.Removed[i].App < diff.Removed[j].App
+		}
+		return diff.Removed[i].InstanceID < diff.Removed[j].InstanceID
+	})
+	return diff
+}
+
+// instanceChanged 实例信息是否变化，忽略每次续约都会更新的 LeaseInfo 与增量合并时的 ActionType
+func instanceChanged(before, after Instance) bool {
+	before.LeaseInfo, after.LeaseInfo = nil, nil
+	before.ActionType, after.ActionType = "", ""
+	return !reflect.DeepEqual(before, after)
+}
+
+// OnRegistryChange 添加服务列表变化时执行的回调，每次刷新后服务列表有变化时按添加顺序执行，可用于排查实例频繁上下线
+func (c *Client) OnRegistryChange(listener RegistryListener) {
+	c.mutex.Lock()
+	c.registryListeners = append(c.registryListeners, listener)
+	c.mutex.Unlock()
+}
+
+// reportRegistryDiff 输出服务列表的变化并执行回调，回调 panic 时记录日志并继续执行后面的回调
+func (c *Client) reportRegistryDiff(diff RegistryDiff) {
+	if diff.Empty() {
+		return
+	}
+	c.opLogger(OpRefresh).Infof("registry changed: %s", diff)
+
+	c.mutex.RLock()
+	listeners := append([]RegistryListener(nil), c.registryListeners...)
+	c.mutex.RUnlock()
+	for _, listener := range listeners {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					c.logger.Errorf("registry listener panic: %v", r)
+				}
+			}()
+			listener(diff)
+		}()
+	}
+}
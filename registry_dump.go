@@ -0,0 +1,78 @@
+package eureka_client
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RegistrySnapshot 导出的服务列表快照
+type RegistrySnapshot struct {
+	XMLName xml.Name `xml:"registrySnapshot" json:"-"`
+	// 导出的时间
+	DumpedAt time.Time `xml:"dumpedAt" json:"dumpedAt"`
+	// 最近一次成功拉取服务列表的时间，从未成功时为零值
+	LastSuccessfulFetch time.Time `xml:"lastSuccessfulFetch" json:"lastSuccessfulFetch"`
+	// 服务列表的代数
+	Generation uint64 `xml:"generation" json:"generation"`
+	// 缓存的服务列表，尚未拉取时为空
+	Applications *Applications `xml:"applications,omitempty" json:"applications,omitempty"`
+}
+
+// RegistrySnapshot 获取当前缓存的服务列表快照
+func (c *Client) RegistrySnapshot() RegistrySnapshot {
+	snapshot := RegistrySnapshot{
+		DumpedAt:            c.Config.clock().Now(),
+		LastSuccessfulFetch: c.Stats().LastSuccessfulFetch,
+	}
+	c.mutex.RLock()
+	snapshot.Generation = c.generation
+	snapshot.Applications = c.Applications.deepCopy()
+	c.mutex.RUnlock()
+	return snapshot
+}
+
+// DumpRegistry 将当前缓存的服务列表与时间戳写入文件，扩展名为 .xml 时使用 XML 格式，否则使用 JSON 格式
+// 先写入同目录下的临时文件再重命名，不会留下写了一半的文件，可用于收集排查服务发现问题的现场
+func (c *Client) DumpRegistry(path string) error {
+	snapshot := c.RegistrySnapshot()
+	var b []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		b, err = xml.MarshalIndent(snapshot, "", "  ")
+		if err == nil {
+			b = append([]byte(xml.Header), b...)
+		}
+	} else {
+		b, err = json.MarshalIndent(snapshot, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(b, '\n'))
+}
+
+// writeFileAtomic 写入同目录下的临时文件后重命名为 path
+func writeFileAtomic(path string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err = f.Write(b); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
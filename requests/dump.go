@@ -0,0 +1,64 @@
+package requests
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+)
+
+// DumpMiddleware 将发出的请求与收到的响应按 http 报文格式写入 w，用于调试
+// body 为 true 时包含请求与响应内容，会把内容读取到内存中，不适合上传或下载大文件
+// 同一个拦截器可以被多个请求并发使用，每个请求与响应的报文完整写入 w 后才写入下一个
+// Authorization 与 Proxy-Authorization 请求头的值替换为 ***，不会输出认证信息
+func DumpMiddleware(w io.Writer, body bool) Middleware {
+	var mutex sync.Mutex
+	write := func(b []byte, err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if err != nil {
+			_, _ = fmt.Fprintf(w, "dump failed: %v\n\n", err)
+			return
+		}
+		_, _ = w.Write(b)
+		_, _ = io.WriteString(w, "\n\n")
+	}
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			write(dumpRequest(req, body))
+			resp, err := next(req)
+			if err != nil {
+				write(nil, err)
+				return resp, err
+			}
+			write(httputil.DumpResponse(resp, body))
+			return resp, nil
+		}
+	}
+}
+
+// sensitiveHeaders 输出报文时隐藏值的请求头
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization"}
+
+// dumpRequest 输出请求报文，输出期间将 req 的请求头临时替换为隐藏了认证信息的副本
+// DumpRequestOut 读取请求内容后会重新设置 req.Body，不能对复制的请求输出报文
+func dumpRequest(req *http.Request, body bool) ([]byte, error) {
+	header := req.Header
+	masked := header.Clone()
+	for _, key := range sensitiveHeaders {
+		if masked.Get(key) != "" {
+			masked.Set(key, "***")
+		}
+	}
+	req.Header = masked
+	defer func() {
+		req.Header = header
+	}()
+	return httputil.DumpRequestOut(req, body)
+}
+
+// Dump 将该请求与响应的报文写入 w，包含请求与响应内容，用于调试
+func (c *Client) Dump(w io.Writer) *Client {
+	return c.Use(DumpMiddleware(w, true))
+}
@@ -0,0 +1,64 @@
+package requests
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxErrorBodySize HTTPError 保存的响应内容的最大长度，超出部分丢弃
+const maxErrorBodySize = 1024
+
+// HTTPError 响应码不符合预期时 StatusOk、Status2xx 返回的错误，包含请求与响应的信息，便于从日志中排查问题
+type HTTPError struct {
+	// 请求方法与地址，地址中的用户名与密码替换为 ***，避免认证信息出现在日志中
+	Method     string
+	URL        string
+	StatusCode int
+	// 响应内容，最多保存 maxErrorBodySize 字节
+	Body []byte
+	// Body 是否被截断
+	Truncated bool
+	// 期望的响应码，例如 200、2xx
+	Expected string
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("%s %s: status code %d is not %s", e.Method, e.URL, e.StatusCode, e.Expected)
+	if len(e.Body) == 0 {
+		return msg
+	}
+	if e.Truncated {
+		return fmt.Sprintf("%s: %s...", msg, e.Body)
+	}
+	return fmt.Sprintf("%s: %s", msg, e.Body)
+}
+
+// redactedURL 将地址中的用户名与密码替换为 ***
+func redactedURL(u *url.URL) string {
+	if u.User == nil {
+		return u.String()
+	}
+	redacted := *u
+	redacted.User = nil
+	return strings.Replace(redacted.String(), "://", "://***@", 1)
+}
+
+// newHTTPError 读取最多 maxErrorBodySize 字节的响应内容后关闭响应，创建 HTTPError
+func newHTTPError(resp *http.Response, expected string) *HTTPError {
+	err := &HTTPError{StatusCode: resp.StatusCode, Expected: expected}
+	if resp.Request != nil {
+		err.Method = resp.Request.Method
+		err.URL = redactedURL(resp.Request.URL)
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize+1))
+	_ = resp.Body.Close()
+	if len(b) > maxErrorBodySize {
+		b = b[:maxErrorBodySize]
+		err.Truncated = true
+	}
+	err.Body = b
+	return err
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
+	"encoding/xml"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/godoes/eureka-client/requests"
@@ -15,11 +19,23 @@ func main() {
 	getText()
 	postForm()
 	postJson()
+	postXml()
 	postMultipart()
+	postMultipartReader()
+	postBytes()
 	handler()
 	save()
 	getJson()
 	customHttp()
+	withContext()
+	withTimeout()
+	withRetry()
+	withTLS()
+	withProxy()
+	withSession()
+	withDump()
+	withPool()
+	withTimings()
 }
 
 func getRow() {
@@ -86,6 +102,22 @@ func postJson() {
 	fmt.Println(text)
 }
 
+func postXml() {
+	type Ping struct {
+		XMLName xml.Name `xml:"ping"`
+		Value   string   `xml:"value"`
+	}
+	var pong Ping
+	err := requests.Post("http://127.0.0.1:8080/ping").
+		Xml(&Ping{Value: "hello"}).
+		Send().
+		Xml(&pong)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(pong.Value)
+}
+
 func postMultipart() {
 	text, err := requests.Post("http://127.0.0.1:8080/ping").
 		Params(url.Values{
@@ -110,6 +142,37 @@ func postMultipart() {
 	fmt.Println(text)
 }
 
+func postMultipartReader() {
+	text, err := requests.Post("http://127.0.0.1:8080/ping").
+		Multipart(requests.FileForm{
+			Parts: []requests.FilePart{
+				{
+					Name:        "report",
+					Filename:    "report.csv",
+					ContentType: "text/csv",
+					Reader:      strings.NewReader("id,name\n1,eureka\n"),
+				},
+			},
+		}).
+		Send().
+		Text()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(text)
+}
+
+func postBytes() {
+	text, err := requests.Post("http://127.0.0.1:8080/ping").
+		Bytes([]byte("hello"), "text/plain").
+		Send().
+		Text()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(text)
+}
+
 func save() {
 	err := requests.Get("https://www.cnblogs.com/bener/p/10683404.html").
 		Send().
@@ -176,3 +239,111 @@ func customHttp() {
 	}
 	fmt.Println(text)
 }
+
+func withContext() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	text, err := requests.Get("http://127.0.0.1:8080/ping").
+		Context(ctx).
+		Send().
+		Text()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(text)
+}
+
+func withTimeout() {
+	text, err := requests.Get("http://127.0.0.1:8080/ping").
+		Timeout(3 * time.Second).
+		Send().
+		Text()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(text)
+}
+
+func withRetry() {
+	text, err := requests.Get("http://127.0.0.1:8080/ping").
+		Retry(3, requests.ExponentialBackoff(100*time.Millisecond, time.Second), nil).
+		Send().
+		Text()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(text)
+}
+
+func withTLS() {
+	text, err := requests.Get("https://127.0.0.1:8443/ping").
+		// self-signed certificate
+		InsecureSkipVerify().
+		Send().
+		Text()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(text)
+}
+
+func withProxy() {
+	text, err := requests.Get("http://127.0.0.1:8080/ping").
+		Proxy("socks5://127.0.0.1:1080").
+		Send().
+		Text()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(text)
+}
+
+func withSession() {
+	session := requests.NewSession("http://127.0.0.1:8080/api/", nil).
+		Header("Accept", "application/json").
+		BearerToken("token")
+
+	text, err := session.Get("ping").Send().Text()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(text)
+}
+
+func withDump() {
+	text, err := requests.Get("http://127.0.0.1:8080/ping").
+		Dump(os.Stderr).
+		Send().
+		Text()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(text)
+}
+
+func withPool() {
+	requests.SetDefaultClient(requests.NewHTTPClient(requests.PoolOptions{
+		MaxIdleConnsPerHost: 16,
+		IdleConnTimeout:     5 * time.Minute,
+		TCPKeepAlive:        30 * time.Second,
+	}))
+
+	text, err := requests.Get("http://127.0.0.1:8080/ping").Send().Text()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(text)
+}
+
+func withTimings() {
+	// applies to every request, including those sent by the eureka client
+	requests.Use(requests.TimingMiddleware(func(req *http.Request, t requests.Timings) {
+		fmt.Println(req.URL.Host, t.DNS, t.Connect, t.TLSHandshake, t.FirstByte, t.Total, t.Reused)
+	}))
+
+	text, err := requests.Get("http://127.0.0.1:8080/ping").Send().Text()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(text)
+}
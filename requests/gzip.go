@@ -0,0 +1,42 @@
+package requests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+)
+
+// Gzip 使用 gzip 压缩请求体并设置 Content-Encoding: gzip，服务端需要支持解压请求体
+func (c *Client) Gzip() *Client {
+	c.gzip = true
+	return c
+}
+
+// gzipBody 压缩请求体，内存中的请求体压缩后仍可重复读取，其他 reader 边读边压缩，
+// 此时返回 *io.PipeReader，请求未发送时调用方需要关闭，否则压缩 goroutine 会一直阻塞
+func gzipBody(body io.Reader) (io.Reader, error) {
+	switch body.(type) {
+	case *bytes.Buffer, *bytes.Reader, *strings.Reader:
+		buf := new(bytes.Buffer)
+		w := gzip.NewWriter(buf)
+		if _, err := io.Copy(w, body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf, nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		w := gzip.NewWriter(pw)
+		_, err := io.Copy(w, body)
+		if err == nil {
+			err = w.Close()
+		}
+		_ = pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
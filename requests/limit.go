@@ -0,0 +1,62 @@
+package requests
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrResponseTooLarge 响应内容超过 MaxResponseBytes 设置的大小
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// MaxResponseBytes 限制响应内容的大小，超过 n 字节时读取响应内容返回 ErrResponseTooLarge，小于等于 0 表示不限制
+// 响应的 Content-Length 已经超过 n 时不读取响应内容，Result.Err 直接为 ErrResponseTooLarge
+func (c *Client) MaxResponseBytes(n int64) *Client {
+	c.maxResponseBytes = n
+	return c
+}
+
+// limitResponse 按 MaxResponseBytes 限制响应内容，Content-Length 超过限制时关闭响应并返回错误
+func (c *Client) limitResponse(result *Result) {
+	n := c.maxResponseBytes
+	if n <= 0 || result.Err != nil {
+		return
+	}
+	if result.Resp.ContentLength > n {
+		_ = result.Resp.Body.Close()
+		result.Err = fmt.Errorf("%w: content length %d exceeds %d bytes", ErrResponseTooLarge, result.Resp.ContentLength, n)
+		return
+	}
+	result.Resp.Body = &limitedBody{ReadCloser: result.Resp.Body, limit: n, remaining: n}
+}
+
+// limitedBody 最多读取 limit 字节，超出时返回 ErrResponseTooLarge
+type limitedBody struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, b.err()
+	}
+	// 多读一个字节用于判断是否超出限制
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		b.exceeded = true
+		return n, b.err()
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
+
+func (b *limitedBody) err() error {
+	return fmt.Errorf("%w: exceeds %d bytes", ErrResponseTooLarge, b.limit)
+}
@@ -0,0 +1,44 @@
+package requests
+
+import (
+	"net/http"
+	"sync"
+)
+
+// RoundTripFunc 发送 http 请求并返回响应
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware 请求拦截器，包装 next 以在请求前后处理，例如添加认证请求头、记录日志、链路跟踪与重试
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+var (
+	globalMiddlewares []Middleware
+	middlewareMutex   sync.RWMutex
+)
+
+// Use 添加对所有请求生效的拦截器，先添加的在外层，并且在 Client.Use 添加的拦截器之外
+func Use(middlewares ...Middleware) {
+	middlewareMutex.Lock()
+	globalMiddlewares = append(globalMiddlewares, middlewares...)
+	middlewareMutex.Unlock()
+}
+
+// Use 添加只对该请求生效的拦截器，先添加的在外层
+func (c *Client) Use(middlewares ...Middleware) *Client {
+	c.middlewares = append(c.middlewares, middlewares...)
+	return c
+}
+
+// chain 按添加顺序包装 send，全局拦截器在最外层
+func (c *Client) chain(send RoundTripFunc) RoundTripFunc {
+	middlewareMutex.RLock()
+	middlewares := make([]Middleware, 0, len(globalMiddlewares)+len(c.middlewares))
+	middlewares = append(middlewares, globalMiddlewares...)
+	middlewareMutex.RUnlock()
+	middlewares = append(middlewares, c.middlewares...)
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		send = middlewares[i](send)
+	}
+	return send
+}
@@ -0,0 +1,81 @@
+package requests
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// PoolOptions 连接池参数，零值表示使用 http.DefaultTransport 的默认值
+// 长期运行、周期性请求同一服务（例如心跳）时，适当增大 MaxIdleConnsPerHost 与 IdleConnTimeout 可以复用连接
+type PoolOptions struct {
+	// 所有主机的最大空闲连接数
+	MaxIdleConns int
+	// 每个主机的最大空闲连接数，http.Transport 的默认值为 2
+	MaxIdleConnsPerHost int
+	// 每个主机的最大连接数，包括正在使用的连接
+	MaxConnsPerHost int
+	// 空闲连接保留的时间，超过后关闭
+	IdleConnTimeout time.Duration
+	// 禁用 http keep-alive，每个请求使用新的连接
+	DisableKeepAlives bool
+	// TCP keep-alive 探测的间隔，小于 0 表示禁用
+	TCPKeepAlive time.Duration
+}
+
+// Apply 将连接池参数应用到 t，零值的参数保持不变
+func (o PoolOptions) Apply(t *http.Transport) {
+	if o.MaxIdleConns > 0 {
+		t.MaxIdleConns = o.MaxIdleConns
+	}
+	if o.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	}
+	if o.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = o.MaxConnsPerHost
+	}
+	if o.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = o.IdleConnTimeout
+	}
+	if o.DisableKeepAlives {
+		t.DisableKeepAlives = true
+	}
+	if o.TCPKeepAlive != 0 {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: o.TCPKeepAlive}
+		t.DialContext = dialer.DialContext
+	}
+}
+
+// NewTransport 复制 http.DefaultTransport 并应用连接池参数
+func NewTransport(opts PoolOptions) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	opts.Apply(t)
+	return t
+}
+
+// NewHTTPClient 创建使用 NewTransport 的 http.Client，可以传给 Request、NewSession 或 SetDefaultClient
+func NewHTTPClient(opts PoolOptions) *http.Client {
+	return &http.Client{Transport: NewTransport(opts)}
+}
+
+var (
+	defaultClient      = http.DefaultClient
+	defaultClientMutex sync.RWMutex
+)
+
+// SetDefaultClient 设置没有指定 http.Client 的请求使用的 http.Client，为 nil 则恢复为 http.DefaultClient
+func SetDefaultClient(client *http.Client) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	defaultClientMutex.Lock()
+	defaultClient = client
+	defaultClientMutex.Unlock()
+}
+
+func getDefaultClient() *http.Client {
+	defaultClientMutex.RLock()
+	defer defaultClientMutex.RUnlock()
+	return defaultClient
+}
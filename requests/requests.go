@@ -2,20 +2,32 @@ package requests
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
-	"errors"
+	"encoding/xml"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 )
 
 // Client 封装了 http 的参数等信息
 type Client struct {
 	// 自定义 Client
 	client *http.Client
+	// 发送请求实际使用的 Client，设置了 TLS 等选项时为复制的 Client
+	sendClient *http.Client
+	// 请求的上下文，为 nil 时使用 context.Background()
+	ctx context.Context
+	// 请求的超时时间，小于等于 0 表示不限制
+	timeout time.Duration
 
 	url    string
 	method string
@@ -24,19 +36,57 @@ type Client struct {
 
 	form      url.Values
 	json      interface{}
+	xml       interface{}
 	multipart FileForm
+	// 原始请求体
+	body io.Reader
+	// 使用 gzip 压缩请求体
+	gzip bool
+
+	// 本次请求使用的 TLS 配置，tlsOwned 表示由 InsecureSkipVerify 等方法创建，可以直接修改
+	tlsConfig *tls.Config
+	tlsOwned  bool
+	// 本次请求使用的代理地址，proxySet 为 true 且地址为空时不使用代理
+	proxy    string
+	proxySet bool
+
+	// 只对该请求生效的拦截器
+	middlewares []Middleware
+	// httptrace 回调
+	traces []*httptrace.ClientTrace
+	// 响应内容的最大字节数，小于等于 0 表示不限制
+	maxResponseBytes int64
+
+	// 重试策略
+	retryAttempts int
+	retryBackoff  BackoffFunc
+	retryOn       RetryOnFunc
 }
 
 // FileForm form 参数和文件参数
 type FileForm struct {
 	Value url.Values
-	File  map[string]string
+	// 参数名称与本地文件路径
+	File map[string]string
+	// 从 io.Reader 读取内容的文件参数，用于上传内存中或动态生成的内容
+	Parts []FilePart
+}
+
+// FilePart 从 Reader 读取内容的文件参数，ContentType 为空时使用 application/octet-stream
+// Reader 由调用方负责关闭
+type FilePart struct {
+	Name        string
+	Filename    string
+	ContentType string
+	Reader      io.Reader
 }
 
 // Result http 响应结果
 type Result struct {
 	Resp *http.Response
 	Err  error
+	// 发送请求的次数，包括 Retry 的重试，请求没有发送时为 0
+	Attempts int
 }
 
 // Get http `GET` 请求
@@ -60,11 +110,18 @@ func Delete(url string) *Client {
 }
 
 // Request 用于自定义请求方式，比如 `HEAD`、`PATCH`、`OPTIONS`、`TRACE`
-// client 参数用于替换 DefaultClient，如果为 nil 则会使用默认的
+// client 参数用于替换 DefaultClient，如果为 nil 则会使用 SetDefaultClient 设置的 http.Client
 func Request(url, method string, client *http.Client) *Client {
 	return newClient(url, method, client)
 }
 
+// Context 设置请求的上下文，用于取消请求或设置截止时间
+// 上下文在 Send 之前已经结束时不会发送请求，Result.Err 为 ctx.Err()；发送过程中结束时中止请求与读取响应内容
+func (c *Client) Context(ctx context.Context) *Client {
+	c.ctx = ctx
+	return c
+}
+
 // Params http 请求中 url 参数
 func (c *Client) Params(params url.Values) *Client {
 	for k, v := range params {
@@ -101,6 +158,28 @@ func (c *Client) Json(json interface{}) *Client {
 	return c
 }
 
+// Xml xml 提交参数
+func (c *Client) Xml(xml interface{}) *Client {
+	c.header.Set("Content-Type", "application/xml")
+	c.xml = xml
+	return c
+}
+
+// Body 以 r 作为请求体提交任意格式的数据，例如 protobuf、XML、纯文本，contentType 为空时不设置 Content-Type
+// r 为 *bytes.Buffer、*bytes.Reader 或 *strings.Reader 之外的类型时请求体无法重复读取，不会重试
+func (c *Client) Body(r io.Reader, contentType string) *Client {
+	if contentType != "" {
+		c.header.Set("Content-Type", contentType)
+	}
+	c.body = r
+	return c
+}
+
+// Bytes 以 b 作为请求体提交任意格式的数据，contentType 为空时不设置 Content-Type
+func (c *Client) Bytes(b []byte, contentType string) *Client {
+	return c.Body(bytes.NewReader(b), contentType)
+}
+
 // Multipart form-data 提交参数
 func (c *Client) Multipart(multipart FileForm) *Client {
 	c.multipart = multipart
@@ -111,6 +190,27 @@ func (c *Client) Multipart(multipart FileForm) *Client {
 func (c *Client) Send() *Result {
 	var result *Result
 
+	if c.ctx != nil && c.ctx.Err() != nil {
+		return &Result{Err: c.ctx.Err()}
+	}
+	release, err := c.prepareClient()
+	if err != nil {
+		return &Result{Err: err}
+	}
+	cancelTimeout := c.withTimeout()
+	cancel := func() {
+		cancelTimeout()
+		release()
+	}
+	defer func() {
+		c.limitResponse(result)
+		if result.Err != nil {
+			cancel()
+			return
+		}
+		result.Resp.Body = &cancelBody{ReadCloser: result.Resp.Body, cancel: cancel}
+	}()
+
 	if c.params != nil && len(c.params) != 0 {
 		// 如果 url 中已经有 query string 参数，则只需要 & 拼接剩下的即可
 		encoded := c.params.Encode()
@@ -122,10 +222,14 @@ func (c *Client) Send() *Result {
 	}
 
 	contentType := c.header.Get("Content-Type")
-	if c.multipart.Value != nil || c.multipart.File != nil {
+	if c.body != nil {
+		result = c.createBody()
+	} else if c.multipart.Value != nil || c.multipart.File != nil || c.multipart.Parts != nil {
 		result = c.createMultipartForm()
 	} else if strings.HasPrefix(contentType, "application/json") {
 		result = c.createJson()
+	} else if strings.HasPrefix(contentType, "application/xml") {
+		result = c.createXml()
 	} else if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
 		result = c.createForm()
 	} else {
@@ -136,68 +240,133 @@ func (c *Client) Send() *Result {
 }
 
 // form-data
+// 请求体通过 io.Pipe 边读文件边发送，上传大文件时内存占用不随文件大小增长，请求体无法重复读取，不会重试
 func (c *Client) createMultipartForm() *Result {
 	var result = new(Result)
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
+	// 先打开所有文件，文件不存在等错误在发送请求前返回
+	files := make(map[string]*os.File, len(c.multipart.File))
 	for name, filename := range c.multipart.File {
 		file, err := os.Open(filename)
 		if err != nil {
+			closeFiles(files)
 			result.Err = err
 			return result
 		}
+		files[name] = file
+	}
 
-		part, err := writer.CreateFormFile(name, filename)
-		if err != nil {
-			result.Err = err
-			return result
-		}
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		defer closeFiles(files)
+		_ = pw.CloseWithError(c.writeMultipart(writer, files))
+	}()
 
-		// TODO 这里的 io.Copy 实现，会把 file 文件都读取到内存里面，然后当做一个 buffer 传给 NewRequest。对于大文件来说会占用很多内存
-		_, err = io.Copy(part, file)
+	req, err := c.newRequest(pr)
+	if err != nil {
+		_ = pr.CloseWithError(err)
+		result.Err = err
+		return result
+	}
+	req.Header = c.header
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	c.doSend(req, result)
+	// 请求失败时服务端可能没有读完请求体，关闭管道让写入的 goroutine 退出
+	_ = pr.Close()
+	return result
+}
+
+// writeMultipart 依次写入文件、Reader 文件参数与表单参数
+func (c *Client) writeMultipart(writer *multipart.Writer, files map[string]*os.File) error {
+	for name, file := range files {
+		part, err := writer.CreateFormFile(name, c.multipart.File[name])
 		if err != nil {
-			result.Err = err
-			return result
+			return err
 		}
+		if _, err = io.Copy(part, file); err != nil {
+			return err
+		}
+	}
 
-		err = file.Close()
+	for _, filePart := range c.multipart.Parts {
+		part, err := writer.CreatePart(filePart.header())
 		if err != nil {
-			result.Err = err
-			return result
+			return err
+		}
+		if filePart.Reader == nil {
+			continue
+		}
+		if _, err = io.Copy(part, filePart.Reader); err != nil {
+			return err
 		}
 	}
 
 	for name, values := range c.multipart.Value {
 		for _, value := range values {
-			_ = writer.WriteField(name, value)
+			if err := writer.WriteField(name, value); err != nil {
+				return err
+			}
 		}
 	}
+	return writer.Close()
+}
 
-	if err := writer.Close(); err != nil {
+// header 文件参数的 MIME 头
+func (p FilePart) header() textproto.MIMEHeader {
+	contentType := p.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(p.Name), quoteEscaper.Replace(p.Filename)))
+	h.Set("Content-Type", contentType)
+	return h
+}
+
+// quoteEscaper 转义 Content-Disposition 中的引号与反斜杠，与 multipart.Writer.CreateFormFile 一致
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// closeFiles 关闭打开的文件
+func closeFiles(files map[string]*os.File) {
+	for _, file := range files {
+		_ = file.Close()
+	}
+}
+
+// application/json
+func (c *Client) createJson() *Result {
+	var result = new(Result)
+
+	b, err := json.Marshal(c.json)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	req, err := c.newRequest(bytes.NewReader(b))
+	if err != nil {
 		result.Err = err
 		return result
 	}
 
-	req, _ := http.NewRequest(c.method, c.url, body)
 	req.Header = c.header
-	req.Header.Set("Content-Type", writer.FormDataContentType())
 	c.doSend(req, result)
 	return result
 }
 
-// application/json
-func (c *Client) createJson() *Result {
+// application/xml
+func (c *Client) createXml() *Result {
 	var result = new(Result)
 
-	b, err := json.Marshal(c.json)
+	b, err := xml.Marshal(c.xml)
 	if err != nil {
 		result.Err = err
 		return result
 	}
 
-	req, err := http.NewRequest(c.method, c.url, bytes.NewReader(b))
+	req, err := c.newRequest(bytes.NewReader(b))
 	if err != nil {
 		result.Err = err
 		return result
@@ -214,7 +383,22 @@ func (c *Client) createForm() *Result {
 
 	form := c.form.Encode()
 
-	req, err := http.NewRequest(c.method, c.url, strings.NewReader(form))
+	req, err := c.newRequest(strings.NewReader(form))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	req.Header = c.header
+	c.doSend(req, result)
+	return result
+}
+
+// raw body
+func (c *Client) createBody() *Result {
+	var result = new(Result)
+
+	req, err := c.newRequest(c.body)
 	if err != nil {
 		result.Err = err
 		return result
@@ -229,7 +413,7 @@ func (c *Client) createForm() *Result {
 func (c *Client) createEmptyBody() *Result {
 	var result = new(Result)
 
-	req, err := http.NewRequest(c.method, c.url, nil)
+	req, err := c.newRequest(nil)
 	if err != nil {
 		result.Err = err
 		return result
@@ -240,41 +424,101 @@ func (c *Client) createEmptyBody() *Result {
 	return result
 }
 
-func (c *Client) doSend(req *http.Request, result *Result) {
-	if c.client != nil {
-		result.Resp, result.Err = c.client.Do(req)
-		return
+// newRequest 使用请求的上下文创建 http.Request，设置了 Gzip 时压缩请求体，设置了 Trace 时添加 httptrace 回调
+func (c *Client) newRequest(body io.Reader) (*http.Request, error) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
 	}
+	// 边读边压缩时写入请求体的 goroutine 的读取端
+	var pipe *io.PipeReader
+	if c.gzip && body != nil {
+		compressed, err := gzipBody(body)
+		if err != nil {
+			return nil, err
+		}
+		pipe, _ = compressed.(*io.PipeReader)
+		body = compressed
+		c.header.Set("Content-Encoding", "gzip")
+	}
+	req, err := http.NewRequestWithContext(ctx, c.method, c.url, body)
+	if err != nil {
+		if pipe != nil {
+			// 请求不会被发送，关闭读取端使压缩 goroutine 退出
+			_ = pipe.CloseWithError(err)
+		}
+		return nil, err
+	}
+	return c.withTraces(req), nil
+}
 
-	result.Resp, result.Err = http.DefaultClient.Do(req)
+func (c *Client) doSend(req *http.Request, result *Result) {
+	send := c.chain(c.sendClient.Do)
+	result.Resp, result.Err = c.retry(func(req *http.Request) (*http.Response, error) {
+		result.Attempts++
+		return send(req)
+	})(req)
 }
 
-// StatusOk 判断 http 响应码是否为 200
+// StatusOk 判断 http 响应码是否为 200，不是时 Result.Err 为 *HTTPError 并关闭响应
 func (r *Result) StatusOk() *Result {
 	if r.Err != nil {
 		return r
 	}
 	if r.Resp.StatusCode != http.StatusOK {
-		r.Err = errors.New("status code is not 200")
+		r.Err = newHTTPError(r.Resp, "200")
 		return r
 	}
 
 	return r
 }
 
-// Status2xx 判断 http 响应码是否为 2xx
+// Status2xx 判断 http 响应码是否为 2xx，不是时 Result.Err 为 *HTTPError 并关闭响应
 func (r *Result) Status2xx() *Result {
 	if r.Err != nil {
 		return r
 	}
 	if r.Resp.StatusCode < http.StatusOK || r.Resp.StatusCode >= http.StatusMultipleChoices {
-		r.Err = errors.New("status code is not match [200, 300)")
+		r.Err = newHTTPError(r.Resp, "2xx")
 		return r
 	}
 
 	return r
 }
 
+// Discard 读完并关闭响应内容以复用连接，不需要响应内容时调用，没有收到响应或响应已关闭时忽略
+func (r *Result) Discard() {
+	if r.Resp == nil || r.Resp.Body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, r.Resp.Body)
+	_ = r.Resp.Body.Close()
+}
+
+// StatusCode http 响应码，没有收到响应时返回 0，不读取响应内容
+func (r *Result) StatusCode() int {
+	if r.Resp == nil {
+		return 0
+	}
+	return r.Resp.StatusCode
+}
+
+// Header http 响应头，没有收到响应时返回空的 http.Header，不读取响应内容
+func (r *Result) Header() http.Header {
+	if r.Resp == nil {
+		return http.Header{}
+	}
+	return r.Resp.Header
+}
+
+// ContentLength 响应内容的长度，长度未知或没有收到响应时返回 -1，不读取响应内容
+func (r *Result) ContentLength() int64 {
+	if r.Resp == nil {
+		return -1
+	}
+	return r.Resp.ContentLength
+}
+
 // Raw 获取 http 响应内容，返回字节数组
 func (r *Result) Raw() ([]byte, error) {
 	if r.Err != nil {
@@ -315,11 +559,25 @@ func (r *Result) Json(v interface{}) error {
 	return json.Unmarshal(b, v)
 }
 
-// Save 获取 http 响应内容，保存为文件
-func (r *Result) Save(name string) error {
+// Xml 获取 http 响应内容，解析 xml
+func (r *Result) Xml(v interface{}) error {
+	b, err := r.Raw()
+	if err != nil {
+		r.Err = err
+		return r.Err
+	}
+
+	return xml.Unmarshal(b, v)
+}
+
+// Save 获取 http 响应内容，保存为文件，可以通过 SaveProgress 获取下载进度、SaveContext 取消下载
+func (r *Result) Save(name string, opts ...SaveOption) error {
 	if r.Err != nil {
 		return r.Err
 	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(r.Resp.Body)
 
 	f, err := os.Create(name)
 	if err != nil {
@@ -330,16 +588,12 @@ func (r *Result) Save(name string) error {
 		_ = f.Close()
 	}(f)
 
-	_, err = io.Copy(f, r.Resp.Body)
+	_, err = io.Copy(f, newSaveReader(r.Resp, opts))
 	if err != nil {
 		r.Err = err
 		return r.Err
 	}
 
-	defer func(Body io.ReadCloser) {
-		_ = Body.Close()
-	}(r.Resp.Body)
-
 	return nil
 }
 
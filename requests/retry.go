@@ -0,0 +1,159 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRetryAfter 遵循的 Retry-After 的最大等待时间，服务端要求等待更久时不再重试，直接返回响应
+const maxRetryAfter = time.Minute
+
+// BackoffFunc 第 attempt 次重试（从 1 开始）前等待的时间
+type BackoffFunc func(attempt int) time.Duration
+
+// RetryOnFunc 根据响应或错误判断是否需要重试
+type RetryOnFunc func(resp *http.Response, err error) bool
+
+// ConstantBackoff 每次重试前等待固定的时间
+func ConstantBackoff(d time.Duration) BackoffFunc {
+	return func(int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff 重试等待时间从 base 开始每次翻倍，不超过 max
+func ExponentialBackoff(base, max time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// DefaultRetryOn 网络错误、429 与 5xx 响应时重试，上下文取消或超时时不重试
+func DefaultRetryOn(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// RetryAfter 解析 429、503 响应的 Retry-After 响应头，支持秒数与 HTTP 日期两种格式
+// 其他响应码、没有该响应头或格式错误时返回 false，日期已过时返回 0
+func RetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := time.Until(date); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// Retry 设置重试策略，attempts 为包括第一次在内的最多请求次数，小于等于 1 表示不重试
+// backoff 为 nil 时立即重试，retryOn 为 nil 时使用 DefaultRetryOn
+// 每次重试都会经过拦截器，请求体无法重复读取时不重试；超时时间（Timeout）包括所有重试与等待的时间
+// 429、503 响应带有 Retry-After 时按服务端要求的时间等待，代替 backoff，要求等待超过 1 分钟时不再重试
+func (c *Client) Retry(attempts int, backoff BackoffFunc, retryOn RetryOnFunc) *Client {
+	if retryOn == nil {
+		retryOn = DefaultRetryOn
+	}
+	c.retryAttempts = attempts
+	c.retryBackoff = backoff
+	c.retryOn = retryOn
+	return c
+}
+
+// retry 按重试策略包装 send
+func (c *Client) retry(send RoundTripFunc) RoundTripFunc {
+	if c.retryAttempts <= 1 {
+		return send
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		for attempt := 1; ; attempt++ {
+			resp, err := send(req)
+			if attempt >= c.retryAttempts || !c.retryOn(resp, err) {
+				return resp, err
+			}
+			var wait time.Duration
+			if c.retryBackoff != nil {
+				wait = c.retryBackoff(attempt)
+			}
+			if retryAfter, ok := RetryAfter(resp); ok {
+				if retryAfter > maxRetryAfter {
+					return resp, err
+				}
+				wait = retryAfter
+			}
+			next, ok := rewind(req)
+			if !ok {
+				return resp, err
+			}
+			if resp != nil {
+				// 读完并关闭响应内容以复用连接
+				_, _ = io.Copy(io.Discard, resp.Body)
+				_ = resp.Body.Close()
+			}
+			if err := sleep(req.Context(), wait); err != nil {
+				return nil, err
+			}
+			req = next
+		}
+	}
+}
+
+// rewind 复制请求用于重试，请求体无法重复读取时返回 false
+func rewind(req *http.Request) (*http.Request, bool) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	next.Body = body
+	return next, true
+}
+
+// sleep 等待 d，上下文结束时提前返回错误
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
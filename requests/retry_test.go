@@ -0,0 +1,66 @@
+package requests_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/godoes/eureka-client/requests"
+)
+
+func TestRetryAttempts(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		retryAfter string
+		attempts   int
+		body       bool
+		wantStatus int
+		wantSent   int
+	}{
+		{"success", []int{200}, "", 3, false, 200, 1},
+		{"retry until success", []int{503, 500, 200}, "", 3, false, 200, 3},
+		{"give up after attempts", []int{500, 500, 500, 200}, "", 3, false, 500, 3},
+		{"no retry", []int{503, 200}, "", 1, false, 503, 1},
+		{"client error not retried", []int{404, 200}, "", 3, false, 404, 1},
+		{"retry after too long", []int{503, 200}, "120", 3, false, 503, 1},
+		{"retry after zero", []int{429, 200}, "0", 3, false, 200, 2},
+		{"body rewound", []int{502, 200}, "", 3, true, 200, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(atomic.AddInt32(&sent, 1))
+				if tt.body {
+					if b, _ := io.ReadAll(r.Body); string(b) != "payload" {
+						t.Errorf("attempt %d body = %q, want payload", n, b)
+					}
+				}
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer srv.Close()
+
+			c := requests.Get(srv.URL)
+			if tt.body {
+				c = requests.Post(srv.URL).Bytes([]byte("payload"), "text/plain")
+			}
+			result := c.Retry(tt.attempts, nil, nil).Send()
+			defer result.Discard()
+			if result.Err != nil {
+				t.Fatal(result.Err)
+			}
+			if result.StatusCode() != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", result.StatusCode(), tt.wantStatus)
+			}
+			if int(sent) != tt.wantSent || result.Attempts != tt.wantSent {
+				t.Fatalf("sent = %d, Attempts = %d, want %d", sent, result.Attempts, tt.wantSent)
+			}
+		})
+	}
+}
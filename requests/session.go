@@ -0,0 +1,97 @@
+package requests
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Session 保存基础地址、默认请求头、认证信息与 http.Client，通过 Get、Post 等方法创建请求，适合多次调用同一服务
+// 先完成配置再创建请求，创建请求的方法可以并发调用，创建的请求复制了默认请求头，修改请求不影响 Session
+type Session struct {
+	baseURL     string
+	client      *http.Client
+	header      http.Header
+	middlewares []Middleware
+}
+
+// NewSession 创建 Session，baseURL 为请求路径的前缀，client 为 nil 时使用 SetDefaultClient 设置的 http.Client
+func NewSession(baseURL string, client *http.Client) *Session {
+	return &Session{
+		baseURL: baseURL,
+		client:  client,
+		header:  make(http.Header),
+	}
+}
+
+// Header 设置默认请求头
+func (s *Session) Header(k, v string) *Session {
+	s.header.Set(k, v)
+	return s
+}
+
+// Headers 设置多个默认请求头
+func (s *Session) Headers(header http.Header) *Session {
+	for k, v := range header {
+		s.header[k] = v
+	}
+	return s
+}
+
+// BasicAuth 使用 Basic 认证
+func (s *Session) BasicAuth(username, password string) *Session {
+	req := http.Request{Header: make(http.Header)}
+	req.SetBasicAuth(username, password)
+	s.header.Set("Authorization", req.Header.Get("Authorization"))
+	return s
+}
+
+// BearerToken 使用 Bearer Token 认证
+func (s *Session) BearerToken(token string) *Session {
+	s.header.Set("Authorization", "Bearer "+token)
+	return s
+}
+
+// Use 添加对该 Session 创建的所有请求生效的拦截器，在请求通过 Client.Use 添加的拦截器之外
+func (s *Session) Use(middlewares ...Middleware) *Session {
+	s.middlewares = append(s.middlewares, middlewares...)
+	return s
+}
+
+// Get http `GET` 请求
+func (s *Session) Get(path string) *Client {
+	return s.Request(path, http.MethodGet)
+}
+
+// Post http `POST` 请求
+func (s *Session) Post(path string) *Client {
+	return s.Request(path, http.MethodPost)
+}
+
+// Put http `PUT` 请求
+func (s *Session) Put(path string) *Client {
+	return s.Request(path, http.MethodPut)
+}
+
+// Delete http `DELETE` 请求
+func (s *Session) Delete(path string) *Client {
+	return s.Request(path, http.MethodDelete)
+}
+
+// Request 用于自定义请求方式，path 为完整的 url（包含 ://）时不拼接基础地址
+func (s *Session) Request(path, method string) *Client {
+	c := newClient(s.url(path), method, s.client)
+	c.header = s.header.Clone()
+	c.middlewares = append([]Middleware(nil), s.middlewares...)
+	return c
+}
+
+// url 拼接基础地址与请求路径，两者之间只保留一个 /
+func (s *Session) url(path string) string {
+	if strings.Contains(path, "://") {
+		return path
+	}
+	if path == "" {
+		return s.baseURL
+	}
+	return strings.TrimSuffix(s.baseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
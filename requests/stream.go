@@ -0,0 +1,76 @@
+package requests
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// Stream 获取 http 响应内容的 reader，不会将响应内容读入内存，使用完后需要调用 Close
+func (r *Result) Stream() (io.ReadCloser, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	return r.Resp.Body, nil
+}
+
+// ProgressFunc 下载进度回调，written 为已写入的字节数，total 为响应的 Content-Length，未知时为 -1
+type ProgressFunc func(written, total int64)
+
+// SaveOption Save 的选项
+type SaveOption func(o *saveOptions)
+
+type saveOptions struct {
+	ctx      context.Context
+	progress ProgressFunc
+}
+
+// SaveProgress 每次写入文件后调用 progress
+func SaveProgress(progress ProgressFunc) SaveOption {
+	return func(o *saveOptions) {
+		o.progress = progress
+	}
+}
+
+// SaveContext ctx 结束时停止下载，Save 返回 ctx.Err()
+func SaveContext(ctx context.Context) SaveOption {
+	return func(o *saveOptions) {
+		o.ctx = ctx
+	}
+}
+
+// saveReader 读取响应内容时检查上下文并回调下载进度
+type saveReader struct {
+	body    io.Reader
+	total   int64
+	written int64
+	saveOptions
+}
+
+// newSaveReader 根据选项包装响应内容，没有选项时直接返回响应内容
+func newSaveReader(resp *http.Response, opts []SaveOption) io.Reader {
+	if len(opts) == 0 {
+		return resp.Body
+	}
+	r := &saveReader{body: resp.Body, total: resp.ContentLength}
+	for _, opt := range opts {
+		opt(&r.saveOptions)
+	}
+	return r
+}
+
+func (r *saveReader) Read(p []byte) (int, error) {
+	if r.ctx != nil {
+		if err := r.ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.body.Read(p)
+	if n > 0 {
+		r.written += int64(n)
+		if r.progress != nil {
+			r.progress(r.written, r.total)
+		}
+	}
+	return n, err
+}
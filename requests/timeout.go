@@ -0,0 +1,40 @@
+package requests
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Timeout 设置请求的超时时间，包括建立连接、发送请求与读取响应内容，与使用的 http.Client 的超时设置无关
+// 与 Context 同时使用时以先到期的为准，小于等于 0 表示不限制
+func (c *Client) Timeout(d time.Duration) *Client {
+	c.timeout = d
+	return c
+}
+
+// withTimeout 在请求的上下文上设置超时时间，返回的函数在响应内容关闭或请求失败时释放上下文
+func (c *Client) withTimeout() context.CancelFunc {
+	if c.timeout <= 0 {
+		return func() {}
+	}
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var cancel context.CancelFunc
+	c.ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	return cancel
+}
+
+// cancelBody 关闭响应内容时释放请求的上下文
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
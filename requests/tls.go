@@ -0,0 +1,36 @@
+package requests
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+)
+
+// TLS 设置本次请求使用的 TLS 配置，不影响使用的 http.Client 与其他请求
+func (c *Client) TLS(config *tls.Config) *Client {
+	c.tlsConfig = config
+	c.tlsOwned = false
+	return c
+}
+
+// InsecureSkipVerify 不校验服务端证书，用于访问自签名证书的地址，仅用于测试或内网环境
+func (c *Client) InsecureSkipVerify() *Client {
+	c.ensureTLSConfig().InsecureSkipVerify = true
+	return c
+}
+
+// RootCAs 使用 pool 中的根证书校验服务端证书，用于访问私有 CA 签发证书的地址
+func (c *Client) RootCAs(pool *x509.CertPool) *Client {
+	c.ensureTLSConfig().RootCAs = pool
+	return c
+}
+
+// ensureTLSConfig 返回本次请求的 TLS 配置，未设置时创建，已通过 TLS 设置时复制后再修改，避免改动调用方的配置
+func (c *Client) ensureTLSConfig() *tls.Config {
+	if c.tlsConfig == nil {
+		c.tlsConfig = &tls.Config{}
+	} else if !c.tlsOwned {
+		c.tlsConfig = c.tlsConfig.Clone()
+	}
+	c.tlsOwned = true
+	return c.tlsConfig
+}
@@ -0,0 +1,114 @@
+package requests
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Trace 为该请求设置 httptrace.ClientTrace 回调，可以多次调用，回调按设置顺序组合
+// 对重试的每次尝试都生效
+func (c *Client) Trace(trace *httptrace.ClientTrace) *Client {
+	c.traces = append(c.traces, trace)
+	return c
+}
+
+// withTraces 在请求的上下文上设置 Trace 添加的回调
+func (c *Client) withTraces(req *http.Request) *http.Request {
+	if len(c.traces) == 0 {
+		return req
+	}
+	ctx := req.Context()
+	for _, trace := range c.traces {
+		ctx = httptrace.WithClientTrace(ctx, trace)
+	}
+	return req.WithContext(ctx)
+}
+
+// Timings 一次请求各阶段的耗时，复用连接时 DNS、Connect、TLSHandshake 为 0
+type Timings struct {
+	// DNS 解析
+	DNS time.Duration
+	// 建立 TCP 连接
+	Connect time.Duration
+	// TLS 握手
+	TLSHandshake time.Duration
+	// 从获取连接到收到响应的第一个字节，即服务端处理请求的时间加网络往返
+	FirstByte time.Duration
+	// 从开始请求到收到响应头
+	Total time.Duration
+	// 是否复用了空闲连接
+	Reused bool
+}
+
+// TimingMiddleware 记录每次请求各阶段的耗时，请求结束（收到响应头或失败）时调用 fn，用于导出延迟指标
+// 通过 Use 添加时对所有请求生效，包括 eureka 客户端发出的请求
+func TimingMiddleware(fn func(req *http.Request, timings Timings)) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			recorder := &timingRecorder{start: time.Now()}
+			req = req.WithContext(httptrace.WithClientTrace(req.Context(), recorder.clientTrace()))
+			resp, err := next(req)
+			fn(req, recorder.timings())
+			return resp, err
+		}
+	}
+}
+
+// timingRecorder 通过 httptrace 回调记录时间点，DNS 与建立连接的回调可能在其他 goroutine 中执行
+type timingRecorder struct {
+	mutex sync.Mutex
+	start time.Time
+
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	gotConn, firstByte        time.Time
+	reused                    bool
+}
+
+func (r *timingRecorder) clientTrace() *httptrace.ClientTrace {
+	now := func(t *time.Time) {
+		r.mutex.Lock()
+		*t = time.Now()
+		r.mutex.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { now(&r.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { now(&r.dnsDone) },
+		ConnectStart:      func(string, string) { now(&r.connectStart) },
+		ConnectDone:       func(string, string, error) { now(&r.connectDone) },
+		TLSHandshakeStart: func() { now(&r.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { now(&r.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.mutex.Lock()
+			r.gotConn = time.Now()
+			r.reused = info.Reused
+			r.mutex.Unlock()
+		},
+		GotFirstResponseByte: func() { now(&r.firstByte) },
+	}
+}
+
+func (r *timingRecorder) timings() Timings {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return Timings{
+		DNS:          since(r.dnsStart, r.dnsDone),
+		Connect:      since(r.connectStart, r.connectDone),
+		TLSHandshake: since(r.tlsStart, r.tlsDone),
+		FirstByte:    since(r.gotConn, r.firstByte),
+		Total:        time.Since(r.start),
+		Reused:       r.reused,
+	}
+}
+
+// since start 到 end 的耗时，任一时间点没有记录时为 0
+func since(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
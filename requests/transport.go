@@ -0,0 +1,71 @@
+package requests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Proxy 通过代理发送本次请求，支持 http、https、socks5 代理，例如 socks5://127.0.0.1:1080，不影响使用的 http.Client 与其他请求
+// proxyURL 为空时不使用代理，包括 HTTP_PROXY 等环境变量中的代理
+func (c *Client) Proxy(proxyURL string) *Client {
+	c.proxy = proxyURL
+	c.proxySet = true
+	return c
+}
+
+// hasTransportOptions 是否设置了需要单独 Transport 的选项，例如 TLS、代理
+func (c *Client) hasTransportOptions() bool {
+	return c.tlsConfig != nil || c.proxySet
+}
+
+// proxyFunc 解析代理地址，返回 http.Transport.Proxy 使用的函数
+func (c *Client) proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	if c.proxy == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(c.proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url %q: missing scheme or host", c.proxy)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// prepareClient 确定发送请求使用的 http.Client
+// 设置了 TLS、代理等传输层选项时，复制原 Transport 并应用这些选项，只用于本次请求，不影响原 http.Client 与其他请求
+// 原 Transport 不是 *http.Transport 时以 http.DefaultTransport 为基础
+// 返回的函数在响应内容关闭或请求失败时关闭该 Transport 的空闲连接
+func (c *Client) prepareClient() (func(), error) {
+	client := c.client
+	if client == nil {
+		client = getDefaultClient()
+	}
+	if !c.hasTransportOptions() {
+		c.sendClient = client
+		return func() {}, nil
+	}
+
+	base, ok := client.Transport.(*http.Transport)
+	if !ok {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	transport := base.Clone()
+	if c.tlsConfig != nil {
+		transport.TLSClientConfig = c.tlsConfig
+	}
+	if c.proxySet {
+		proxy, err := c.proxyFunc()
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = proxy
+	}
+
+	cloned := *client
+	cloned.Transport = transport
+	c.sendClient = &cloned
+	return transport.CloseIdleConnections, nil
+}
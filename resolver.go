@@ -0,0 +1,142 @@
+package eureka_client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+var (
+	// ErrNoInstance 注册表中没有可用（UP）的服务实例
+	ErrNoInstance = errors.New("no available instance")
+)
+
+// Resolver 基于注册表的客户端负载均衡，轮询选择 UP 状态的服务实例
+type Resolver struct {
+	client  *Client
+	counter uint64
+
+	// SkipRecentlyRemoved 跳过最近从注册表中消失后又重新出现的实例，避免立即使用被回收的实例ID
+	// 所有实例都被跳过时仍会从中选择
+	SkipRecentlyRemoved bool
+}
+
+// NewResolver 创建负载均衡解析器
+func NewResolver(client *Client) *Resolver {
+	return &Resolver{client: client}
+}
+
+// Instances 获取应用下所有 UP 状态的服务实例
+func (r *Resolver) Instances(app string) []Instance {
+	if r.client.Config.NameMapper == nil {
+		// eureka 服务端统一使用大写的应用名称
+		app = strings.ToUpper(app)
+	}
+	instances := r.client.GetApplicationInstance(app)
+	up := make([]Instance, 0, len(instances))
+	recycled := make([]Instance, 0)
+	for _, instance := range instances {
+		if instance.Status != "UP" {
+			continue
+		}
+		if r.SkipRecentlyRemoved && r.client.IsRecentlyRemoved(instance.InstanceID) {
+			recycled = append(recycled, instance)
+			continue
+		}
+		up = append(up, instance)
+	}
+	if len(up) == 0 {
+		return recycled
+	}
+	return up
+}
+
+// Pick 轮询选择一个 UP 状态的服务实例
+func (r *Resolver) Pick(app string) (*Instance, error) {
+	return r.pick(app, nil)
+}
+
+// pick 轮询选择一个 UP 状态的服务实例，跳过 exclude 中的实例
+func (r *Resolver) pick(app string, exclude map[string]bool) (*Instance, error) {
+	instances := r.Instances(app)
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoInstance, app)
+	}
+	n := atomic.AddUint64(&r.counter, 1)
+	for i := range instances {
+		instance := instances[(n+uint64(i))%uint64(len(instances))]
+		if !exclude[instance.InstanceID] {
+			return &instance, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoInstance, app)
+}
+
+// ResolveServiceURL 选择一个服务实例，返回其访问地址，例如：http://127.0.0.1:8080
+// scheme 与端口由实例的元数据决定，详见 InstanceURL
+func (r *Resolver) ResolveServiceURL(app string) (string, error) {
+	instance, err := r.Pick(app)
+	if err != nil {
+		return "", err
+	}
+	return InstanceURL(instance), nil
+}
+
+// ResolveServiceTarget 选择一个服务实例，返回不带 scheme 的 host:port，可直接用于 grpc.Dial 等场景
+// 元数据 grpc=true 且设置了 grpc.port 时使用 grpc 端口，否则与 ResolveServiceURL 的端口相同
+func (r *Resolver) ResolveServiceTarget(app string) (string, error) {
+	instance, err := r.Pick(app)
+	if err != nil {
+		return "", err
+	}
+	return instanceTarget(instance), nil
+}
+
+// InstanceURL 获取服务实例的 http 访问地址，用于 Transport、代理等改写请求的场景，scheme 按以下顺序确定：
+//   - 元数据 scheme 指定的 http 或 https
+//   - 元数据 securePortEnabled=true 或者 SecurePort 已启用时为 https，并使用 SecurePort
+//   - 其他情况为 http
+//
+// grpc 端口不会出现在该地址中，需要时使用 ResolveServiceTarget
+func InstanceURL(instance *Instance) string {
+	scheme, host := instanceAddress(instance)
+	return scheme + "://" + host
+}
+
+// instanceAddress 根据元数据获取服务实例的 http 或 https scheme 与 host:port
+func instanceAddress(instance *Instance) (scheme, host string) {
+	port := 80
+	if instance.Port != nil {
+		port = instance.Port.Int()
+	}
+	scheme = "http"
+	if metadataValue(instance.Metadata, "securePortEnabled") == "true" || instance.SecurePort.IsEnabled() {
+		scheme = "https"
+		if p := instance.SecurePort.Int(); p != 0 {
+			port = p
+		}
+	}
+	if s := strings.ToLower(metadataValue(instance.Metadata, "scheme")); s == "http" || s == "https" {
+		scheme = s
+	}
+	return scheme, net.JoinHostPort(instance.IPAddr, strconv.Itoa(port))
+}
+
+// instanceTarget 服务实例的 host:port，元数据 grpc=true 时端口优先使用元数据 grpc.port
+func instanceTarget(instance *Instance) string {
+	if metadataValue(instance.Metadata, "grpc") == "true" {
+		if p, err := strconv.Atoi(metadataValue(instance.Metadata, "grpc.port")); err == nil {
+			return net.JoinHostPort(instance.IPAddr, strconv.Itoa(p))
+		}
+	}
+	_, host := instanceAddress(instance)
+	return host
+}
+
+// metadataValue 获取元数据的字符串值，不存在则返回空字符串
+func metadataValue(metadata Metadata, key string) string {
+	return metadata.Get(key)
+}
@@ -0,0 +1,60 @@
+package eureka_client
+
+import (
+	"os"
+	"syscall"
+)
+
+// ShutdownHook 收到退出信号后在注销前或注销后执行的钩子，sig 为收到的信号
+type ShutdownHook func(sig os.Signal)
+
+// defaultShutdownSignals 未配置 Config.ShutdownSignals 时监听的退出信号
+var defaultShutdownSignals = []os.Signal{syscall.SIGTERM, syscall.SIGINT}
+
+// shutdownSignals 获取配置的退出信号，未配置则使用 SIGTERM 与 SIGINT
+func (c *Config) shutdownSignals() []os.Signal {
+	if len(c.ShutdownSignals) == 0 {
+		return defaultShutdownSignals
+	}
+	return c.ShutdownSignals
+}
+
+// BeforeDeregister 添加收到退出信号后、注销实例前执行的钩子，例如停止接收新的请求，按添加顺序执行
+func (c *Client) BeforeDeregister(hook ShutdownHook) {
+	c.mutex.Lock()
+	c.beforeDeregister = append(c.beforeDeregister, hook)
+	c.mutex.Unlock()
+}
+
+// AfterDeregister 添加注销实例后、退出进程前执行的钩子，例如关闭数据库连接、刷新日志，按添加顺序执行
+func (c *Client) AfterDeregister(hook ShutdownHook) {
+	c.mutex.Lock()
+	c.afterDeregister = append(c.afterDeregister, hook)
+	c.mutex.Unlock()
+}
+
+func (c *Client) beforeHooks() []ShutdownHook {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return append([]ShutdownHook(nil), c.beforeDeregister...)
+}
+
+func (c *Client) afterHooks() []ShutdownHook {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return append([]ShutdownHook(nil), c.afterDeregister...)
+}
+
+// runShutdownHooks 依次执行钩子，钩子 panic 时记录日志并继续执行后面的钩子
+func (c *Client) runShutdownHooks(hooks []ShutdownHook, sig os.Signal) {
+	for _, hook := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					c.logger.Errorf("shutdown hook panic: %v", r)
+				}
+			}()
+			hook(sig)
+		}()
+	}
+}
@@ -0,0 +1,102 @@
+package eureka_client
+
+// ClientState 客户端对外的状态，用于监控面板与就绪判断
+type ClientState int
+
+const (
+	// StateNotStarted 客户端尚未启动
+	StateNotStarted ClientState = iota
+	// StateRegistering 客户端已启动，实例尚未成功注册或续约
+	StateRegistering
+	// StateRegistered 实例已注册，心跳正常
+	StateRegistered
+	// StateDegraded 心跳连续失败，客户端正在退避重试，心跳恢复后回到 StateRegistered
+	StateDegraded
+	// StateShutdown 实例已注销或客户端已停止，不会再变化
+	StateShutdown
+)
+
+var clientStateNames = [...]string{"NOT_STARTED", "REGISTERING", "REGISTERED", "DEGRADED", "SHUTDOWN"}
+
+func (s ClientState) String() string {
+	if s < 0 || int(s) >= len(clientStateNames) {
+		return "UNKNOWN"
+	}
+	return clientStateNames[s]
+}
+
+// State 获取客户端当前的状态
+func (c *Client) State() ClientState {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	c.refreshStateLocked()
+	return c.clientState
+}
+
+// StateChanges 返回接收客户端状态变化的 channel，每次调用返回新的 channel，创建后先收到当前状态
+// channel 只保留最新的状态，接收不及时时丢弃未接收的旧状态；进入 StateShutdown 后关闭
+func (c *Client) StateChanges() <-chan ClientState {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	c.refreshStateLocked()
+	ch := make(chan ClientState, 1)
+	ch <- c.clientState
+	if c.clientState == StateShutdown {
+		close(ch)
+	} else {
+		c.stateListeners = append(c.stateListeners, ch)
+	}
+	return ch
+}
+
+// updateState 重新计算客户端状态，状态变化时通知 StateChanges 返回的 channel
+// 生命周期变化、注销以及每次心跳后调用，调用时不能持有 c.mutex
+func (c *Client) updateState() {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	c.refreshStateLocked()
+}
+
+// refreshStateLocked 重新计算客户端状态，调用时需持有 c.stateMutex
+// 在锁内计算并通知，保证并发更新时状态按发生的顺序通知，不会在 StateShutdown 之后通知其他状态
+func (c *Client) refreshStateLocked() {
+	if c.clientState == StateShutdown {
+		return
+	}
+	state := c.currentState()
+	if state == c.clientState {
+		return
+	}
+	c.logger.Infof("client state %s -> %s", c.clientState, state)
+	c.clientState = state
+	for _, ch := range c.stateListeners {
+		offerLatest(ch, state)
+		if state == StateShutdown {
+			close(ch)
+		}
+	}
+	if state == StateShutdown {
+		c.stateListeners = nil
+	}
+}
+
+// currentState 根据生命周期、是否已注销与心跳统计计算客户端状态
+func (c *Client) currentState() ClientState {
+	switch c.lifecycle() {
+	case stateNew:
+		return StateNotStarted
+	case stateStopping, stateStopped:
+		return StateShutdown
+	}
+	if c.isDeregistered() {
+		return StateShutdown
+	}
+	stats := c.stats.snapshot()
+	if stats.Degraded {
+		return StateDegraded
+	}
+	if stats.LastSuccessfulRenewal.IsZero() {
+		return StateRegistering
+	}
+	return StateRegistered
+}
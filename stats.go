@@ -0,0 +1,138 @@
+package eureka_client
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ClientStats 客户端运行统计
+type ClientStats struct {
+	// 最近一次成功心跳的时间
+	LastSuccessfulHeartbeat time.Time
+	// 最近一次成功续约（心跳或注册）的时间
+	LastSuccessfulRenewal time.Time
+	// 最近一次成功拉取服务列表的时间
+	LastSuccessfulFetch time.Time
+	// 连续心跳失败次数，成功后清零
+	ConsecutiveHeartbeatFailures int
+	// 连续拉取服务列表失败次数，成功后清零
+	ConsecutiveFetchFailures int
+	// 心跳失败总次数
+	HeartbeatFailures int64
+	// 心跳时服务端返回 409 冲突的次数，包含在 HeartbeatFailures 中
+	HeartbeatConflicts int64
+	// 心跳连续失败达到 Config.ReRegisterAfterFailures 后重新注册的次数
+	ReRegistrations int64
+	// 拉取服务列表失败总次数
+	FetchFailures int64
+	// 最近一次心跳或拉取服务列表时服务端返回 503，此时拉取服务列表的间隔会逐渐变长
+	ServerUnavailable bool
+	// 心跳连续失败进入降级模式，心跳间隔按指数退避，成功后恢复
+	Degraded bool
+	// 缓存的服务列表中应用的数量
+	RegistryApplications int
+	// 缓存的服务列表中实例的数量
+	RegistryInstances int
+}
+
+// maxHeartbeatHistory 保留的最近心跳记录数量
+const maxHeartbeatHistory = 20
+
+// HeartbeatEvent 一次心跳的结果
+type HeartbeatEvent struct {
+	Time time.Time `json:"time"`
+	// 失败原因，成功时为空
+	Error string `json:"error,omitempty"`
+}
+
+// statsRecorder 记录客户端运行统计
+type statsRecorder struct {
+	mutex sync.RWMutex
+	stats ClientStats
+	// 最近的心跳记录，按时间先后排列
+	history []HeartbeatEvent
+}
+
+func (r *statsRecorder) heartbeat(err error, now time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	event := HeartbeatEvent{Time: now}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	if len(r.history) >= maxHeartbeatHistory {
+		r.history = append(r.history[:0], r.history[1:]...)
+	}
+	r.history = append(r.history, event)
+	r.stats.ServerUnavailable = errors.Is(err, ErrServerUnavailable)
+	if err != nil {
+		r.stats.ConsecutiveHeartbeatFailures++
+		r.stats.HeartbeatFailures++
+		if err == ErrConflict {
+			r.stats.HeartbeatConflicts++
+		}
+		return
+	}
+	r.stats.ConsecutiveHeartbeatFailures = 0
+	r.stats.LastSuccessfulHeartbeat = now
+}
+
+func (r *statsRecorder) renewed(now time.Time) {
+	r.mutex.Lock()
+	r.stats.LastSuccessfulRenewal = now
+	r.mutex.Unlock()
+}
+
+func (r *statsRecorder) fetch(apps *Applications, err error, now time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.stats.ServerUnavailable = errors.Is(err, ErrServerUnavailable)
+	if err != nil {
+		r.stats.ConsecutiveFetchFailures++
+		r.stats.FetchFailures++
+		return
+	}
+	r.stats.ConsecutiveFetchFailures = 0
+	r.stats.LastSuccessfulFetch = now
+	r.stats.RegistryApplications = len(apps.Applications)
+	r.stats.RegistryInstances = 0
+	for _, app := range apps.Applications {
+		r.stats.RegistryInstances += len(app.Instances)
+	}
+}
+
+func (r *statsRecorder) reRegistered() {
+	r.mutex.Lock()
+	r.stats.ReRegistrations++
+	r.mutex.Unlock()
+}
+
+func (r *statsRecorder) setDegraded(degraded bool) {
+	r.mutex.Lock()
+	r.stats.Degraded = degraded
+	r.mutex.Unlock()
+}
+
+func (r *statsRecorder) snapshot() ClientStats {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.stats
+}
+
+// heartbeatHistory 复制最近的心跳记录
+func (r *statsRecorder) heartbeatHistory() []HeartbeatEvent {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]HeartbeatEvent(nil), r.history...)
+}
+
+// HeartbeatHistory 获取最近的心跳记录（最多 20 条），按时间先后排列
+func (c *Client) HeartbeatHistory() []HeartbeatEvent {
+	return c.stats.heartbeatHistory()
+}
+
+// Stats 获取客户端运行统计，可用于实现自定义的健康检查
+func (c *Client) Stats() ClientStats {
+	return c.stats.snapshot()
+}
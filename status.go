@@ -0,0 +1,156 @@
+package eureka_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// 实例状态
+const (
+	StatusUp           = "UP"
+	StatusDown         = "DOWN"
+	StatusStarting     = "STARTING"
+	StatusOutOfService = "OUT_OF_SERVICE"
+	StatusUnknown      = "UNKNOWN"
+)
+
+// ErrInvalidStatus 实例状态不是 UP、DOWN、STARTING、OUT_OF_SERVICE 或 UNKNOWN
+var ErrInvalidStatus = errors.New("invalid instance status")
+
+// stateLock 实例的状态锁，保护 Status、OverriddenStatus 与 lastDirtyTimestamp，心跳与注册在后台 goroutine 中读取这些字段
+// 锁属于实例所在的 RegisteredInstance，不同实例互不阻塞；不属于 RegisteredInstance 的实例不会被并发修改，返回新的锁即不加锁
+func (i *Instance) stateLock() *sync.RWMutex {
+	if i.stateMutex != nil {
+		return i.stateMutex
+	}
+	return new(sync.RWMutex)
+}
+
+// instanceState 心跳与注册使用的实例状态
+type instanceState struct {
+	status           string
+	overriddenStatus string
+	lastDirty        string
+}
+
+// state 加锁读取实例状态
+func (i *Instance) state() instanceState {
+	mutex := i.stateLock()
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return instanceState{status: i.Status, overriddenStatus: i.OverriddenStatus, lastDirty: i.LastDirtyTimestamp}
+}
+
+// effectiveStatus 服务端对外提供的实例状态，设置了覆盖状态时以覆盖状态为准
+func (s instanceState) effectiveStatus() string {
+	if s.overriddenStatus != "" && s.overriddenStatus != StatusUnknown {
+		return s.overriddenStatus
+	}
+	return s.status
+}
+
+// snapshot 加锁复制实例，注册时发送复制的实例，避免与修改状态并发
+func (i *Instance) snapshot() Instance {
+	mutex := i.stateLock()
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return i.deepCopy()
+}
+
+// setOverriddenStatus 加锁修改实例的覆盖状态
+func (i *Instance) setOverriddenStatus(status string) {
+	mutex := i.stateLock()
+	mutex.Lock()
+	i.OverriddenStatus = status
+	mutex.Unlock()
+}
+
+// validStatus 是否为 eureka 支持的实例状态
+func validStatus(status string) bool {
+	switch status {
+	case StatusUp, StatusDown, StatusStarting, StatusOutOfService, StatusUnknown:
+		return true
+	}
+	return false
+}
+
+// SetStatus 修改实例状态并同步到服务端，见 SetStatusContext
+func (ri *RegisteredInstance) SetStatus(status string) error {
+	return ri.SetStatusContext(context.Background(), status)
+}
+
+// SetStatusContext 修改实例状态并同步到服务端，返回 nil 时本地与服务端的状态一致
+// OUT_OF_SERVICE 通过覆盖状态设置，心跳会继续发送；其他状态先删除已有的覆盖状态，
+// 再更新 Status 与 lastDirtyTimestamp 后重新注册，注册失败时恢复原来的 Status
+// 同一实例的多次调用依次执行，心跳在所有状态下都会继续发送，停止心跳需要注销实例
+func (ri *RegisteredInstance) SetStatusContext(ctx context.Context, status string) error {
+	if !validStatus(status) {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, status)
+	}
+	ri.statusMutex.Lock()
+	defer ri.statusMutex.Unlock()
+	ri.guard()
+
+	current := ri.Instance.state()
+	if status == StatusOutOfService {
+		if current.overriddenStatus == StatusOutOfService {
+			return nil
+		}
+		return ri.OutOfServiceContext(ctx)
+	}
+	if current.overriddenStatus != "" && current.overriddenStatus != StatusUnknown {
+		err := deleteStatusOverride(ctx, ri.Config.httpClient(), ri.Config.DefaultZone, ri.App, ri.InstanceID, status)
+		if err != nil && err != ErrNotFound {
+			return err
+		}
+		ri.Instance.setOverriddenStatus(StatusUnknown)
+	}
+	if current.status == status {
+		return nil
+	}
+
+	ri.stateMutex.Lock()
+	ri.Instance.Status = status
+	ri.Instance.setDirtyLocked(ri.Config.clock().Now())
+	ri.stateMutex.Unlock()
+	if err := ri.RegisterContext(ctx); err != nil {
+		ri.stateMutex.Lock()
+		ri.Instance.Status = current.status
+		ri.stateMutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// EffectiveStatus 实例对外的状态，设置了覆盖状态（例如 OUT_OF_SERVICE）时返回覆盖状态
+func (ri *RegisteredInstance) EffectiveStatus() string {
+	return ri.Instance.state().effectiveStatus()
+}
+
+// SetInstanceStatus 修改客户端实例的状态并同步到服务端，例如预热完成前设置为 STARTING，故障时设置为 DOWN，
+// 状态变化时执行 LifecycleHooks.OnStatusChange
+func (c *Client) SetInstanceStatus(status string) error {
+	return c.SetInstanceStatusContext(context.Background(), status)
+}
+
+// SetInstanceStatusContext 与 SetInstanceStatus 相同，ctx 取消或超时时中止请求
+func (c *Client) SetInstanceStatusContext(ctx context.Context, status string) error {
+	if c.isDeregistered() {
+		return fmt.Errorf("%w: instance de-registered", ErrNotRegistered)
+	}
+	old := c.Instance.EffectiveStatus()
+	if err := c.Instance.SetStatusContext(ctx, status); err != nil {
+		c.opLogger(OpStatusOverride).Error(fmt.Sprintf("set instance status %s failed", status), err)
+		return err
+	}
+	c.opLogger(OpStatusOverride).Infof("instance status is %s", status)
+	c.onStatusChange(old, c.Instance.EffectiveStatus())
+	return nil
+}
+
+// InstanceStatus 客户端实例对外的状态，设置了覆盖状态时返回覆盖状态
+func (c *Client) InstanceStatus() string {
+	return c.Instance.EffectiveStatus()
+}
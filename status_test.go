@@ -0,0 +1,40 @@
+package eureka_client_test
+
+import (
+	"testing"
+	"time"
+
+	eureka "github.com/godoes/eureka-client"
+	"github.com/godoes/eureka-client/eurekatest"
+)
+
+func TestSetInstanceStatus(t *testing.T) {
+	srv := eurekatest.NewServer()
+	defer srv.Close()
+	c := eureka.NewClient(&eureka.Config{DefaultZone: srv.Zone(), App: "st", Port: 8080, RenewalIntervalInSecs: 1})
+	c.Start()
+	defer c.Stop()
+	if !srv.WaitRegistered("ST", c.Config.InstanceID, 3*time.Second) {
+		t.Fatal("instance not registered")
+	}
+
+	tests := []struct {
+		status    string
+		effective string
+	}{
+		{eureka.StatusDown, eureka.StatusDown},
+		{eureka.StatusOutOfService, eureka.StatusOutOfService},
+		{eureka.StatusUp, eureka.StatusUp},
+	}
+	for _, tt := range tests {
+		if err := c.SetInstanceStatus(tt.status); err != nil {
+			t.Fatalf("SetInstanceStatus(%s): %v", tt.status, err)
+		}
+		if got := c.Instance.EffectiveStatus(); got != tt.effective {
+			t.Fatalf("EffectiveStatus() = %s after %s, want %s", got, tt.status, tt.effective)
+		}
+	}
+	if err := c.SetInstanceStatus("bogus"); err == nil {
+		t.Fatal("SetInstanceStatus(bogus) should fail")
+	}
+}
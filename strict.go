@@ -0,0 +1,86 @@
+package eureka_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrSchemaMismatch 严格模式下服务端返回的数据与客户端的结构不一致，例如出现未知字段或缺少必需字段
+var ErrSchemaMismatch = errors.New("eureka payload schema mismatch")
+
+var strictDecoding int32
+
+// SetStrictDecoding 设置是否严格解析服务端返回的服务列表
+// 严格模式下出现未知字段或缺少必需字段时返回 ErrSchemaMismatch，用于在 CI/集成环境中尽早发现服务端升级导致的数据结构变化
+func SetStrictDecoding(strict bool) {
+	var v int32
+	if strict {
+		v = 1
+	}
+	atomic.StoreInt32(&strictDecoding, v)
+}
+
+// isStrictDecoding 是否为严格解析模式
+func isStrictDecoding() bool {
+	return atomic.LoadInt32(&strictDecoding) == 1
+}
+
+// decodeApplications 解析服务列表，严格模式下禁止未知字段并检查必需字段
+func decodeApplications(b []byte) (*Applications, error) {
+	type Result struct {
+		Applications *Applications `json:"applications"`
+	}
+	apps := new(Applications)
+	res := &Result{
+		Applications: apps,
+	}
+	if !isStrictDecoding() {
+		if err := json.Unmarshal(b, res); err != nil {
+			return nil, err
+		}
+		return apps, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(res); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSchemaMismatch, err)
+	}
+	if res.Applications == nil {
+		return nil, fmt.Errorf("%w: missing field applications", ErrSchemaMismatch)
+	}
+	if err := validateApplications(apps); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSchemaMismatch, err)
+	}
+	return apps, nil
+}
+
+// validateApplications 检查服务列表的必需字段
+func validateApplications(apps *Applications) error {
+	for i, app := range apps.Applications {
+		if app.Name == "" {
+			return fmt.Errorf("missing field application[%d].name", i)
+		}
+		for j, instance := range app.Instances {
+			required := []struct {
+				name  string
+				value string
+			}{
+				{"instanceId", instance.InstanceID},
+				{"app", instance.App},
+				{"hostName", instance.HostName},
+				{"ipAddr", instance.IPAddr},
+				{"status", instance.Status},
+			}
+			for _, field := range required {
+				if field.value == "" {
+					return fmt.Errorf("missing field application[%s].instance[%d].%s", app.Name, j, field.name)
+				}
+			}
+		}
+	}
+	return nil
+}
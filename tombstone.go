@@ -0,0 +1,80 @@
+package eureka_client
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// RemovedInstance 最近从注册表中消失的实例
+type RemovedInstance struct {
+	Instance  Instance
+	RemovedAt time.Time
+}
+
+// tombstones 记录最近从注册表中消失的实例，超过保留时间后清除
+type tombstones struct {
+	mutex sync.RWMutex
+	items map[string]RemovedInstance
+}
+
+// update 对比新旧注册表，记录消失的实例，并清除超过保留时间的记录
+func (t *tombstones) update(old, current *Applications, window time.Duration, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.items == nil {
+		t.items = make(map[string]RemovedInstance)
+	}
+	for id, removed := range t.items {
+		if now.Sub(removed.RemovedAt) > window {
+			delete(t.items, id)
+		}
+	}
+	if old == nil || window <= 0 {
+		return
+	}
+
+	exists := make(map[string]bool)
+	for _, app := range current.Applications {
+		for _, instance := range app.Instances {
+			exists[instance.InstanceID] = true
+		}
+	}
+	for _, app := range old.Applications {
+		for _, instance := range app.Instances {
+			if !exists[instance.InstanceID] {
+				t.items[instance.InstanceID] = RemovedInstance{Instance: instance, RemovedAt: now}
+			}
+		}
+	}
+}
+
+func (t *tombstones) has(instanceID string) bool {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	_, ok := t.items[instanceID]
+	return ok
+}
+
+func (t *tombstones) list() []RemovedInstance {
+	t.mutex.RLock()
+	removed := make([]RemovedInstance, 0, len(t.items))
+	for _, item := range t.items {
+		removed = append(removed, item)
+	}
+	t.mutex.RUnlock()
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].RemovedAt.Before(removed[j].RemovedAt)
+	})
+	return removed
+}
+
+// RecentlyRemoved 获取 Config.TombstoneWindowSecs 内从注册表中消失的实例，按消失时间排序
+func (c *Client) RecentlyRemoved() []RemovedInstance {
+	return c.tombstones.list()
+}
+
+// IsRecentlyRemoved 实例是否在 Config.TombstoneWindowSecs 内从注册表中消失过，重新出现的实例仍会保留记录直到过期
+func (c *Client) IsRecentlyRemoved(instanceID string) bool {
+	return c.tombstones.has(instanceID)
+}
@@ -0,0 +1,91 @@
+package eureka_client
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Scheme 通过服务名称访问的 url scheme，例如：eureka://order-service/api/orders
+const Scheme = "eureka"
+
+// Transport 实现 http.RoundTripper，将 eureka://APP/path 请求改写为某个 UP 状态实例的地址
+// 连接失败时会换一个实例重试，重试仅用于没有请求体或者请求体可以重复读取的请求
+type Transport struct {
+	// Resolver 负载均衡解析器
+	Resolver *Resolver
+	// Base 实际发送请求的 RoundTripper，为 nil 则使用 http.DefaultTransport
+	Base http.RoundTripper
+	// MaxRetries 连接失败时换实例重试的次数
+	MaxRetries int
+}
+
+// NewTransport 创建 Transport，默认重试 2 次
+func NewTransport(client *Client, base http.RoundTripper) *Transport {
+	return &Transport{
+		Resolver:   NewResolver(client),
+		Base:       base,
+		MaxRetries: 2,
+	}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != Scheme {
+		return t.base().RoundTrip(req)
+	}
+
+	app := req.URL.Hostname()
+	tried := make(map[string]bool)
+	var lastErr error
+	for attempt := 0; attempt <= t.MaxRetries; attempt++ {
+		instance, err := t.Resolver.pick(app, tried)
+		if err != nil {
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, err
+		}
+		tried[instance.InstanceID] = true
+
+		r, err := rewriteRequest(req, instance, attempt)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.base().RoundTrip(r)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if req.Body != nil && req.GetBody == nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// rewriteRequest 复制请求并将 url 改写为服务实例的地址
+func rewriteRequest(req *http.Request, instance *Instance, attempt int) (*http.Request, error) {
+	target, err := url.Parse(InstanceURL(instance))
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.URL.Scheme = target.Scheme
+	r.URL.Host = target.Host
+	r.Host = target.Host
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
@@ -0,0 +1,53 @@
+package eureka_client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/godoes/eureka-client/requests"
+)
+
+// ErrServerUnavailable 服务端返回 503，通常表示服务端正在启动、处于自我保护模式或者过载
+// 可以通过 errors.Is(err, ErrServerUnavailable) 判断，与客户端自身的错误区分
+var ErrServerUnavailable = errors.New("eureka server unavailable")
+
+// ServerUnavailableError 服务端返回 503 时的错误
+type ServerUnavailableError struct {
+	// 操作名称，例如 register、heartbeat
+	Op string
+	// eureka 服务端地址，认证信息已替换为 ***
+	Zone string
+	// 服务端通过 Retry-After 建议的重试时间，为空表示没有
+	RetryAfter string
+}
+
+func (e *ServerUnavailableError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrServerUnavailable, e.Op, e.Zone)
+}
+
+// Is 支持 errors.Is(err, ErrServerUnavailable)
+func (e *ServerUnavailableError) Is(target error) bool {
+	return target == ErrServerUnavailable
+}
+
+// unavailableFetchBackoffBound 服务端不可用时拉取服务列表间隔的最大倍数
+const unavailableFetchBackoffBound = 8
+
+// checkUnavailable 响应为 503 时将 result.Err 设置为 ServerUnavailableError，同时隐藏错误信息中地址携带的认证信息
+func checkUnavailable(op, zone string, result *requests.Result) *requests.Result {
+	result = redactResult(result)
+	if result.Err == nil && result.StatusCode() == http.StatusServiceUnavailable {
+		result.Err = &ServerUnavailableError{
+			Op:         op,
+			Zone:       RedactURL(zone),
+			RetryAfter: result.Header().Get("Retry-After"),
+		}
+	}
+	return result
+}
+
+// ServerUnavailable 最近一次心跳或拉取服务列表时服务端是否返回 503
+func (c *Client) ServerUnavailable() bool {
+	return c.stats.snapshot().ServerUnavailable
+}
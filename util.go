@@ -1,22 +1,50 @@
 package eureka_client
 
 import (
-	"net"
+	"math/rand"
+	"time"
 )
 
-// GetLocalIP 获取本地 ip
-func GetLocalIP() (ip string) {
-	addresses, err := net.InterfaceAddrs()
-	if err != nil {
+// backoff 连续失败 failures 次后的指数退避间隔，最大为 d 的 bound 倍
+func backoff(d time.Duration, failures, bound int) time.Duration {
+	multiple := 1
+	for i := 0; i < failures && multiple < bound; i++ {
+		multiple *= 2
+	}
+	if bound > 0 && multiple > bound {
+		multiple = bound
+	}
+	return d * time.Duration(multiple)
+}
+
+// offerLatest 向容量为 1 的 channel 发送 v，channel 已满时丢弃未接收的旧值，只保留最新的值
+func offerLatest[T any](ch chan T, v T) {
+	select {
+	case ch <- v:
 		return
+	default:
+	}
+	select {
+	case <-ch:
+	default:
+	}
+	select {
+	case ch <- v:
+	default:
+	}
+}
+
+// jitter 在 d 的 ±percent% 内随机取值，避免大量实例同时启动后心跳与拉取服务列表的请求集中在同一时刻
+func jitter(d time.Duration, percent int) time.Duration {
+	if percent <= 0 || d <= 0 {
+		return d
+	}
+	if percent > 100 {
+		percent = 100
 	}
-	for _, address := range addresses {
-		// check the address type and if it is not a loopback the display it
-		if ipNet, ok := address.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && !ipNet.IP.IsLinkLocalUnicast() {
-			if ipNet.IP.To4() != nil {
-				return ipNet.IP.String()
-			}
-		}
+	delta := int64(d) * int64(percent) / 100
+	if delta <= 0 {
+		return d
 	}
-	return
+	return d - time.Duration(delta) + time.Duration(rand.Int63n(2*delta+1))
 }
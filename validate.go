@@ -0,0 +1,62 @@
+package eureka_client
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidConfig NewClientE 校验配置失败，具体原因见错误信息
+var ErrInvalidConfig = errors.New("invalid config")
+
+// NewClientE 校验配置并创建客户端，配置有误时返回 ErrInvalidConfig，而不是像 NewClient 那样使用默认值继续
+// 校验 eureka 服务端地址、数据格式、端口、元数据、优先网段，未设置 IP 时检测本地 IP，检测失败同样返回错误
+func NewClientE(config *Config, opts ...Option) (*Client, error) {
+	if err := validateConfig(config); err != nil {
+		return nil, err
+	}
+	return NewClient(config, opts...), nil
+}
+
+// validateConfig 校验配置，未设置 IP 时检测本地 IP 并写入配置，避免 DefaultConfig 在检测失败时回退到其他地址
+func validateConfig(config *Config) error {
+	if config.DefaultZone != "" {
+		u, err := url.Parse(config.DefaultZone)
+		if err != nil {
+			return fmt.Errorf("%w: DefaultZone %q: %v", ErrInvalidConfig, RedactURL(config.DefaultZone), redactError(err))
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: DefaultZone %q: must be an absolute http or https url", ErrInvalidConfig, RedactURL(config.DefaultZone))
+		}
+	}
+	if ct := strings.ToLower(config.ContentType); ct != "" && !isXML(ct) && !strings.Contains(ct, "json") {
+		return fmt.Errorf("%w: ContentType %q: must be %s or %s", ErrInvalidConfig, config.ContentType, ContentTypeJSON, ContentTypeXML)
+	}
+	if config.Port < 0 || config.Port > 65535 {
+		return fmt.Errorf("%w: Port %d: out of range", ErrInvalidConfig, config.Port)
+	}
+	if config.SecurePort < 0 || config.SecurePort > 65535 {
+		return fmt.Errorf("%w: SecurePort %d: out of range", ErrInvalidConfig, config.SecurePort)
+	}
+	if config.DataCenterInfo != nil && config.DataCenterInfo.Name == "" {
+		return fmt.Errorf("%w: DataCenterInfo.Name is empty", ErrInvalidConfig)
+	}
+	if _, err := ConvertMetadata(config.Metadata); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if _, err := parseNetworks(config.PreferredNetworks); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if config.IP == "" {
+		ip, err := detectIP(config)
+		if err != nil {
+			return fmt.Errorf("%w: detect local IP: %v", ErrInvalidConfig, err)
+		}
+		if ip == "" {
+			return fmt.Errorf("%w: detect local IP: no IPv4 address found", ErrInvalidConfig)
+		}
+		config.IP = ip
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package eureka_client
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 与 eureka 服务端交互的数据格式，通过 Config.ContentType 设置
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeXML  = "application/xml"
+)
+
+// xmlInstance 注册实例时 XML 格式的请求体
+type xmlInstance struct {
+	XMLName xml.Name `xml:"instance"`
+	*Instance
+}
+
+// isXML contentType 是否为 XML 格式
+func isXML(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "xml")
+}
+
+// decodeApplicationsXML 解析 XML 格式的服务列表，严格模式下检查必需字段
+func decodeApplicationsXML(b []byte) (*Applications, error) {
+	apps := new(Applications)
+	if err := xml.Unmarshal(b, apps); err != nil {
+		return nil, err
+	}
+	if isStrictDecoding() {
+		if err := validateApplications(apps); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSchemaMismatch, err)
+		}
+	}
+	return apps, nil
+}
+
+// MarshalXML 元数据以 key 作为元素名称，例如 <metadata><version>1.0</version></metadata>
+func (m Metadata) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := e.EncodeElement(m[k], xml.StartElement{Name: xml.Name{Local: k}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML 解析以 key 作为元素名称的元数据，忽略 metadata 元素的属性
+func (m *Metadata) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	metadata := make(Metadata)
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			var value string
+			if err = d.DecodeElement(&value, &t); err != nil {
+				return err
+			}
+			metadata[t.Name.Local] = value
+		case xml.EndElement:
+			*m = metadata
+			return nil
+		}
+	}
+}
+
+// contentType 获取配置的数据格式，未配置则使用 ContentTypeJSON
+func (c *Config) contentType() string {
+	if c.ContentType == "" {
+		return ContentTypeJSON
+	}
+	return c.ContentType
+}